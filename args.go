@@ -0,0 +1,262 @@
+package goflags
+
+import (
+	"flag"
+	"strings"
+)
+
+// UnknownFlags returns the flags not defined in the FlagSet, with their values,
+// found by Parse when IgnoreUnknownFlags is set, in the order they were provided.
+func (flagSet *FlagSet) UnknownFlags() []string {
+	return flagSet.unknownFlags
+}
+
+// Args returns the arguments remaining after the flags
+func (flagSet *FlagSet) Args() []string {
+	return flagSet.CommandLine().Args()
+}
+
+// NArg returns the number of arguments remaining after the flags
+func (flagSet *FlagSet) NArg() int {
+	return flagSet.CommandLine().NArg()
+}
+
+// Arg returns the i'th argument remaining after the flags,
+// or an empty string if it doesn't exist.
+func (flagSet *FlagSet) Arg(i int) string {
+	return flagSet.CommandLine().Arg(i)
+}
+
+// Remainder returns the arguments provided after the -- terminator as is,
+// for example to pass them to a subprocess, or nil if it was not provided.
+func (flagSet *FlagSet) Remainder() []string {
+	return flagSet.remainder
+}
+
+// findRemainder returns the arguments after the -- terminator once the processed arguments are parsed
+func (flagSet *FlagSet) findRemainder(args []string) []string {
+	remaining := flagSet.Args()
+	if consumed := len(args) - len(remaining); consumed > 0 && args[consumed-1] == "--" {
+		return append([]string{}, remaining...)
+	}
+	for i, arg := range remaining {
+		if arg == "--" {
+			return append([]string{}, remaining[i+1:]...)
+		}
+	}
+	return nil
+}
+
+// flagArg is a command line argument providing a flag
+type flagArg struct {
+	dashes   string
+	name     string
+	value    string
+	hasValue bool
+}
+
+// parseFlagArg parses an argument providing a flag, returning false if it is not one
+func parseFlagArg(arg string) (flagArg, bool) {
+	if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+		return flagArg{}, false
+	}
+	name := strings.TrimLeft(arg, "-")
+	parsed := flagArg{dashes: arg[:len(arg)-len(name)], name: name}
+	if index := strings.Index(name, "="); index >= 0 {
+		parsed.name, parsed.value, parsed.hasValue = name[:index], name[index+1:], true
+	}
+	return parsed, true
+}
+
+func (arg flagArg) String() string {
+	if arg.hasValue {
+		return arg.dashes + arg.name + "=" + arg.value
+	}
+	return arg.dashes + arg.name
+}
+
+// isBoolFlag reports whether the flag can be provided without a value
+func isBoolFlag(currentFlag *flag.Flag) bool {
+	boolFlag, ok := currentFlag.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// filterUnknownFlags removes the flags not defined in the FlagSet from the arguments,
+// along with their value when it is provided as the next argument. The arguments
+// after the first one which isn't a flag are kept as is.
+func (flagSet *FlagSet) filterUnknownFlags(args []string) (known, unknown []string) {
+	for i := 0; i < len(args); i++ {
+		arg, ok := parseFlagArg(args[i])
+		if !ok {
+			return append(known, args[i:]...), unknown
+		}
+		takesValue := !arg.hasValue && i+1 < len(args)
+
+		currentFlag := flagSet.CommandLine().Lookup(arg.name)
+		if currentFlag == nil && (arg.name == "h" || arg.name == "help") {
+			known = append(known, args[i]) // handled by the flag package
+			continue
+		}
+		if currentFlag != nil {
+			known = append(known, args[i])
+			if takesValue && !isBoolFlag(currentFlag) {
+				i++
+				known = append(known, args[i])
+			}
+			continue
+		}
+		unknown = append(unknown, args[i])
+		if takesValue && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			unknown = append(unknown, args[i])
+		}
+	}
+	return known, unknown
+}
+
+// lookupFold returns the flag with the name, ignoring its case if no flag has the exact name
+func (flagSet *FlagSet) lookupFold(name string) *flag.Flag {
+	if currentFlag := flagSet.CommandLine().Lookup(name); currentFlag != nil {
+		return currentFlag
+	}
+	var found *flag.Flag
+	flagSet.CommandLine().VisitAll(func(fl *flag.Flag) {
+		if found == nil && strings.EqualFold(fl.Name, name) {
+			found = fl
+		}
+	})
+	return found
+}
+
+// foldFlagNames replaces the names of the flags provided in the arguments
+// with the names they were defined with, ignoring their case.
+func (flagSet *FlagSet) foldFlagNames(args []string) []string {
+	folded := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg, ok := parseFlagArg(args[i])
+		if !ok {
+			return append(folded, args[i:]...)
+		}
+		currentFlag := flagSet.lookupFold(arg.name)
+		if currentFlag == nil {
+			folded = append(folded, args[i])
+			continue
+		}
+		arg.name = currentFlag.Name
+		folded = append(folded, arg.String())
+		if !arg.hasValue && !isBoolFlag(currentFlag) && i+1 < len(args) {
+			i++
+			folded = append(folded, args[i])
+		}
+	}
+	return folded
+}
+
+// foldConfigKeys replaces the config keys matching a flag name ignoring
+// their case with the name the flag was defined with.
+func (flagSet *FlagSet) foldConfigKeys(data map[string]interface{}, lines map[string]int) (map[string]interface{}, map[string]int) {
+	folded := make(map[string]interface{}, len(data))
+	foldedLines := make(map[string]int, len(lines))
+	for key, value := range data {
+		name := key
+		if currentFlag := flagSet.lookupFold(key); currentFlag != nil {
+			name = currentFlag.Name
+		}
+		folded[name] = value
+		if line, ok := lines[key]; ok {
+			foldedLines[name] = line
+		}
+	}
+	for key, line := range lines {
+		if _, ok := data[key]; !ok {
+			foldedLines[key] = line
+		}
+	}
+	return folded, foldedLines
+}
+
+// expandShortFlags splits the clusters of single letter flags like -vqf into
+// separate flags, the last flag of a cluster taking the rest of the cluster
+// as its value if it isn't a bool flag. Arguments providing a defined flag
+// or which can't be split are kept as is.
+func (flagSet *FlagSet) expandShortFlags(args []string) []string {
+	expanded := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg, ok := parseFlagArg(args[i])
+		if !ok {
+			return append(expanded, args[i:]...)
+		}
+		currentFlag := flagSet.CommandLine().Lookup(arg.name)
+		if currentFlag == nil && arg.dashes == "-" && len(arg.name) > 1 {
+			if cluster, takesValue, ok := flagSet.splitShortFlags(arg); ok {
+				expanded = append(expanded, cluster...)
+				if takesValue && i+1 < len(args) {
+					i++
+					expanded = append(expanded, args[i])
+				}
+				continue
+			}
+		}
+		expanded = append(expanded, args[i])
+		if currentFlag != nil && !arg.hasValue && !isBoolFlag(currentFlag) && i+1 < len(args) {
+			i++
+			expanded = append(expanded, args[i])
+		}
+	}
+	return expanded
+}
+
+// splitShortFlags splits a cluster of single letter flags, reporting whether its
+// last flag takes the next argument as value, and returning false if any of them
+// is not defined.
+func (flagSet *FlagSet) splitShortFlags(arg flagArg) (cluster []string, takesValue, ok bool) {
+	for i, letter := range arg.name {
+		name := string(letter)
+		currentFlag := flagSet.CommandLine().Lookup(name)
+		if currentFlag == nil {
+			return nil, false, false
+		}
+		rest := arg.name[i+len(name):]
+		switch {
+		case rest == "":
+			cluster = append(cluster, flagArg{dashes: "-", name: name, value: arg.value, hasValue: arg.hasValue}.String())
+			takesValue = !arg.hasValue && !isBoolFlag(currentFlag)
+		case !isBoolFlag(currentFlag):
+			if arg.hasValue {
+				rest += "=" + arg.value
+			}
+			return append(cluster, "-"+name, rest), false, true
+		default:
+			cluster = append(cluster, "-"+name)
+		}
+	}
+	return cluster, takesValue, true
+}
+
+// intersperse moves the flags provided after positional arguments before them,
+// so that they are parsed too. The arguments after the -- terminator are kept as is.
+func (flagSet *FlagSet) intersperse(args []string) []string {
+	var flags, positionals []string
+	for i := 0; i < len(args); i++ {
+		arg, ok := parseFlagArg(args[i])
+		if args[i] == "--" {
+			return append(append(flags, positionals...), args[i:]...)
+		}
+		if !ok {
+			positionals = append(positionals, args[i])
+			continue
+		}
+		flags = append(flags, args[i])
+		if arg.hasValue || i+1 >= len(args) {
+			continue
+		}
+		currentFlag := flagSet.CommandLine().Lookup(arg.name)
+		switch {
+		case currentFlag != nil && !isBoolFlag(currentFlag),
+			currentFlag == nil && flagSet.IgnoreUnknownFlags && !strings.HasPrefix(args[i+1], "-"):
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	return append(flags, positionals...)
+}
@@ -0,0 +1,180 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreUnknownFlags(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.IgnoreUnknownFlags = true
+
+	var threads int
+	var verbose bool
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+
+	err := flagSet.ParseArgs([]string{"-t", "10", "-proxy", "http://127.0.0.1", "-verbose", "--debug", "-timeout=5", "-x", "-quiet", "target.com"})
+	require.Nil(t, err, "could not ignore unknown flags")
+	require.Equal(t, 10, threads)
+	require.True(t, verbose)
+	require.Equal(t, []string{"-proxy", "http://127.0.0.1", "--debug", "-timeout=5", "-x", "-quiet", "target.com"}, flagSet.UnknownFlags())
+
+	flagSet = NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads")
+	require.NotNil(t, flagSet.ParseArgs([]string{"-proxy", "http://127.0.0.1"}), "could ignore unknown flags by default")
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("Rate-Limit: 50"), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath(configFile)
+	flagSet.CaseInsensitive = true
+
+	var timeout time.Duration
+	var verbose, version bool
+	var rateLimit int
+	flagSet.DurationVar(&timeout, "timeout", time.Second, "Timeout")
+	flagSet.BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	flagSet.BoolVarP(&version, "version", "V", false, "Show version")
+	flagSet.IntVar(&rateLimit, "rate-limit", 10, "Rate limit")
+
+	err = flagSet.ParseArgs([]string{"-TIMEOUT", "5s", "--Verbose", "target.com"})
+	require.Nil(t, err, "could not parse flags ignoring case")
+	require.Equal(t, 5*time.Second, timeout)
+	require.True(t, verbose)
+	require.False(t, version, "could not prefer exact name")
+	require.Equal(t, 50, rateLimit, "could not match config key ignoring case")
+	require.Equal(t, []string{"target.com"}, flagSet.CommandLine().Args())
+
+	flagSet = NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.CommandLine().SetOutput(ioutil.Discard)
+	flagSet.DurationVar(&timeout, "timeout", time.Second, "Timeout")
+	require.NotNil(t, flagSet.ParseArgs([]string{"-Timeout=5s"}), "could ignore case by default")
+}
+
+func TestCombinedShortFlags(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.CombinedShortFlags = true
+
+	var verbose, quiet, silent bool
+	var file, output string
+	flagSet.BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	flagSet.BoolVarP(&quiet, "quiet", "q", false, "Quiet output")
+	flagSet.BoolVarP(&silent, "silent", "sv", false, "Silent output")
+	flagSet.StringVarP(&file, "file", "f", "", "Input file")
+	flagSet.StringVarP(&output, "output", "o", "", "Output file")
+
+	err := flagSet.ParseArgs([]string{"-vqf", "input.txt", "-oout.txt", "target.com", "-vq"})
+	require.Nil(t, err, "could not parse combined short flags")
+	require.True(t, verbose)
+	require.True(t, quiet)
+	require.False(t, silent)
+	require.Equal(t, "input.txt", file)
+	require.Equal(t, "out.txt", output)
+	require.Equal(t, []string{"target.com", "-vq"}, flagSet.CommandLine().Args())
+
+	verbose = false
+	require.Nil(t, flagSet.ParseArgs([]string{"-sv"}), "could not parse multi-letter short flag")
+	require.True(t, silent, "could split defined multi-letter short flag")
+	require.False(t, verbose)
+}
+
+func TestDoubleDashFlags(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.DoubleDashUsage = true
+
+	var output, format string
+	var threads int
+	var verbose, debug bool
+	var timeout time.Duration
+	var targets StringSlice
+	flagSet.StringVarP(&output, "output", "o", "", "Output file")
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flagSet.BoolVar(&debug, "debug", true, "Debug output")
+	flagSet.DurationVar(&timeout, "timeout", time.Second, "Timeout")
+	flagSet.StringSliceVar(&targets, "targets", nil, "Targets")
+	flagSet.EnumVar(&format, "format", "json", []string{"json", "yaml"}, "Output format")
+
+	err := flagSet.ParseArgs([]string{"--output", "out.txt", "--threads=10", "--verbose", "--debug=false", "--timeout", "5s", "--targets", "a.com,b.com", "--targets=c.com", "--format", "yaml"})
+	require.Nil(t, err, "could not parse double dash flags")
+	require.Equal(t, "out.txt", output)
+	require.Equal(t, 10, threads)
+	require.True(t, verbose)
+	require.False(t, debug)
+	require.Equal(t, 5*time.Second, timeout)
+	require.Equal(t, StringSlice{"a.com", "b.com", "c.com"}, targets)
+	require.Equal(t, "yaml", format)
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "-o, --output string")
+	require.Contains(t, usage.String(), "--verbose")
+}
+
+func TestRemainingArgs(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var verbose bool
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+
+	require.Nil(t, flagSet.ParseArgs([]string{"-verbose", "--", "-verbose", "--", "x"}), "could not parse arguments")
+	require.True(t, verbose)
+	require.Equal(t, []string{"-verbose", "--", "x"}, flagSet.Args())
+	require.Equal(t, 3, flagSet.NArg())
+	require.Equal(t, "--", flagSet.Arg(1))
+	require.Equal(t, []string{"-verbose", "--", "x"}, flagSet.Remainder())
+
+	require.Nil(t, flagSet.ParseArgs([]string{"target.com", "--", "-v"}), "could not parse arguments")
+	require.Equal(t, []string{"target.com", "--", "-v"}, flagSet.Args())
+	require.Equal(t, []string{"-v"}, flagSet.Remainder())
+
+	require.Nil(t, flagSet.ParseArgs([]string{"target.com"}), "could not parse arguments")
+	require.Nil(t, flagSet.Remainder())
+}
+
+func TestInterspersed(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.Interspersed = true
+
+	var json bool
+	var output string
+	flagSet.BoolVar(&json, "json", false, "JSON output")
+	flagSet.StringVarP(&output, "output", "o", "", "Output file")
+
+	require.Nil(t, flagSet.ParseArgs([]string{"target.com", "-json", "other.com", "-o", "out.txt", "--", "-json"}), "could not parse interspersed flags")
+	require.True(t, json)
+	require.Equal(t, "out.txt", output)
+	require.Equal(t, []string{"target.com", "other.com", "--", "-json"}, flagSet.Args())
+	require.Equal(t, []string{"-json"}, flagSet.Remainder())
+
+	flagSet = NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	json = false
+	flagSet.BoolVar(&json, "json", false, "JSON output")
+	require.Nil(t, flagSet.ParseArgs([]string{"target.com", "-json"}), "could not parse arguments")
+	require.False(t, json, "could parse interspersed flags by default")
+	require.Equal(t, []string{"target.com", "-json"}, flagSet.Args())
+}
@@ -0,0 +1,383 @@
+package goflags
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AWSOptions configures how values are resolved from AWS
+type AWSOptions struct {
+	// Region is the AWS region, AWS_REGION or AWS_DEFAULT_REGION by default
+	Region string
+	// Profile is the profile of the shared credentials file, AWS_PROFILE or default by default
+	Profile string
+	// Endpoint replaces the regional endpoints of the services, for example for LocalStack
+	Endpoint string
+	// CacheTTL is how long resolved values are cached in memory, 5 minutes by default
+	CacheTTL time.Duration
+	// Timeout is the timeout of the requests, 10 seconds by default
+	Timeout time.Duration
+}
+
+// defaultAWSCacheTTL is the default duration resolved AWS values are cached for
+const defaultAWSCacheTTL = 5 * time.Minute
+
+// awsCredentialsExpiryWindow is how long before their expiration temporary credentials are renewed
+const awsCredentialsExpiryWindow = time.Minute
+
+// awsCredentials are the credentials signing AWS requests
+type awsCredentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"Token"`
+	// Expiration is the expiration time of temporary credentials, zero for long-term ones
+	Expiration time.Time `json:"Expiration"`
+}
+
+// awsResolver resolves references to SSM parameters and Secrets Manager secrets
+type awsResolver struct {
+	options *AWSOptions
+	client  *http.Client
+
+	mutex             sync.Mutex
+	cache             map[string]awsCachedValue
+	cachedCredentials *awsCredentials
+}
+
+// awsCachedValue is a resolved AWS value with its expiration time
+type awsCachedValue struct {
+	value   string
+	expires time.Time
+}
+
+// UseAWS makes Parse resolve values set from the environment or config files like
+// ssm:/app/token with the SSM Parameter Store parameter, decrypted if needed, and
+// like aws-sm:app/credentials with the Secrets Manager secret, or one of its JSON
+// keys with aws-sm:app/credentials#token.
+//
+// Credentials are read from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN environment variables, the ECS container credentials
+// endpoint or the shared credentials file, in this order, and are cached until
+// they expire.
+//
+// NOTE: this is a subset of the credential chain of the AWS SDKs. The EC2 instance
+// metadata service (IMDS), web identity tokens (AWS_WEB_IDENTITY_TOKEN_FILE, used by
+// IRSA on EKS), SSO, assume role profiles and the region of ~/.aws/config are not
+// supported. Applications relying on them can register a resolver built on the
+// default config of aws-sdk-go-v2 with AddValueResolver instead.
+func (flagSet *FlagSet) UseAWS(options *AWSOptions) {
+	if options == nil {
+		options = &AWSOptions{}
+	}
+	resolver := &awsResolver{
+		options: options,
+		client:  remoteConfigClient(&RemoteConfigOptions{Timeout: options.Timeout}),
+		cache:   make(map[string]awsCachedValue),
+	}
+	flagSet.AddValueResolver("ssm", func(reference string) (string, error) {
+		return resolver.cached("ssm:"+reference, func() (string, error) {
+			return resolver.getParameter(reference)
+		})
+	})
+	flagSet.AddValueResolver("aws-sm", func(reference string) (string, error) {
+		return resolver.cached("aws-sm:"+reference, func() (string, error) {
+			return resolver.getSecret(reference)
+		})
+	})
+}
+
+// cached returns the cached value of a reference, resolving it if it expired
+func (resolver *awsResolver) cached(reference string, resolve func() (string, error)) (string, error) {
+	resolver.mutex.Lock()
+	defer resolver.mutex.Unlock()
+
+	if cached, ok := resolver.cache[reference]; ok && time.Now().Before(cached.expires) {
+		return cached.value, nil
+	}
+	value, err := resolve()
+	if err != nil {
+		return "", err
+	}
+	ttl := resolver.options.CacheTTL
+	if ttl == 0 {
+		ttl = defaultAWSCacheTTL
+	}
+	resolver.cache[reference] = awsCachedValue{value: value, expires: time.Now().Add(ttl)}
+	return value, nil
+}
+
+// getParameter returns the decrypted value of an SSM parameter
+func (resolver *awsResolver) getParameter(name string) (string, error) {
+	var response struct {
+		Parameter struct {
+			Value string
+		}
+	}
+	request := map[string]interface{}{"Name": name, "WithDecryption": true}
+	if err := resolver.call("ssm", "AmazonSSM.GetParameter", request, &response); err != nil {
+		return "", errors.Wrapf(err, "could not get ssm parameter %s", name)
+	}
+	return response.Parameter.Value, nil
+}
+
+// getSecret returns the value of a Secrets Manager secret, or of a key of a JSON secret
+func (resolver *awsResolver) getSecret(reference string) (string, error) {
+	secretID, key := reference, ""
+	if separator := strings.LastIndex(reference, "#"); separator >= 0 {
+		secretID, key = reference[:separator], reference[separator+1:]
+	}
+
+	var response struct {
+		SecretString string
+	}
+	request := map[string]interface{}{"SecretId": secretID}
+	if err := resolver.call("secretsmanager", "secretsmanager.GetSecretValue", request, &response); err != nil {
+		return "", errors.Wrapf(err, "could not get secret %s", secretID)
+	}
+	if key == "" {
+		return response.SecretString, nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(response.SecretString), &values); err != nil {
+		return "", errors.Wrapf(err, "secret %s is not a JSON object", secretID)
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", errors.Errorf("key %q not found in secret %s", key, secretID)
+	}
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// call sends a signed request to an AWS JSON API and decodes its response
+func (resolver *awsResolver) call(service, target string, input, output interface{}) error {
+	region := resolver.options.Region
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region == "" {
+			region = os.Getenv(name)
+		}
+	}
+	if region == "" {
+		return errors.New("no aws region, set AWS_REGION")
+	}
+	credentials, err := resolver.credentials()
+	if err != nil {
+		return err
+	}
+
+	endpoint := resolver.options.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + service + "." + region + ".amazonaws.com"
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	request.Header.Set("X-Amz-Target", target)
+	signAWSRequest(request, body, credentials, region, service, time.Now().UTC())
+
+	response, err := resolver.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		var apiError struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(response.Body).Decode(&apiError)
+		return errors.Errorf("unexpected status %s: %s %s", response.Status, apiError.Type, apiError.Message)
+	}
+	return json.NewDecoder(response.Body).Decode(output)
+}
+
+// credentials returns the AWS credentials of the environment, cached until they
+// expire. It is called with the mutex of the resolver held.
+func (resolver *awsResolver) credentials() (awsCredentials, error) {
+	if cached := resolver.cachedCredentials; cached != nil {
+		if cached.Expiration.IsZero() || time.Now().Add(awsCredentialsExpiryWindow).Before(cached.Expiration) {
+			return *cached, nil
+		}
+	}
+	credentials, err := resolver.loadCredentials()
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	resolver.cachedCredentials = &credentials
+	return credentials, nil
+}
+
+// loadCredentials reads the AWS credentials from the first provider of the chain having them
+func (resolver *awsResolver) loadCredentials() (awsCredentials, error) {
+	if accessKeyID, secretAccessKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); accessKeyID != "" && secretAccessKey != "" {
+		return awsCredentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	containerURL := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relativeURI := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relativeURI != "" {
+		containerURL = "http://169.254.170.2" + relativeURI
+	}
+	if containerURL != "" {
+		request, err := http.NewRequest(http.MethodGet, containerURL, nil)
+		if err != nil {
+			return awsCredentials{}, errors.Wrap(err, "invalid container credentials url")
+		}
+		if token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"); token != "" {
+			request.Header.Set("Authorization", token)
+		}
+		response, err := resolver.client.Do(request)
+		if err != nil {
+			return awsCredentials{}, errors.Wrap(err, "could not get container credentials")
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			return awsCredentials{}, errors.Errorf("could not get container credentials: unexpected status %s", response.Status)
+		}
+		var credentials awsCredentials
+		if err := json.NewDecoder(response.Body).Decode(&credentials); err != nil {
+			return awsCredentials{}, errors.Wrap(err, "could not decode container credentials")
+		}
+		return credentials, nil
+	}
+	return resolver.sharedCredentials()
+}
+
+// sharedCredentials reads the credentials of the profile from the shared credentials file
+func (resolver *awsResolver) sharedCredentials() (awsCredentials, error) {
+	profile := resolver.options.Profile
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	file := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if file == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return awsCredentials{}, errors.New("no aws credentials found")
+		}
+		file = filepath.Join(homeDir, ".aws", "credentials")
+	}
+	content, err := os.Open(file)
+	if err != nil {
+		return awsCredentials{}, errors.New("no aws credentials found")
+	}
+	defer content.Close()
+
+	var credentials awsCredentials
+	var section string
+	scanner := bufio.NewScanner(content)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if section != profile || len(parts) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(parts[1])
+		switch strings.TrimSpace(parts[0]) {
+		case "aws_access_key_id":
+			credentials.AccessKeyID = value
+		case "aws_secret_access_key":
+			credentials.SecretAccessKey = value
+		case "aws_session_token":
+			credentials.SessionToken = value
+		}
+	}
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return awsCredentials{}, errors.Errorf("no aws credentials found for profile %s", profile)
+	}
+	return credentials, nil
+}
+
+// signAWSRequest signs a request with AWS Signature Version 4
+func signAWSRequest(request *http.Request, body []byte, credentials awsCredentials, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	request.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := request.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		path,
+		canonicalQuery(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	request.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+credentials.AccessKeyID+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// canonicalQuery returns the query string of a request as signed by AWS Signature Version 4
+func canonicalQuery(query url.Values) string {
+	return strings.Replace(query.Encode(), "+", "%20", -1)
+}
+
+func sha256Hex(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, content string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(content))
+	return mac.Sum(nil)
+}
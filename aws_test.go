@@ -0,0 +1,120 @@
+package goflags
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAWSRequest(t *testing.T) {
+	// example request of the AWS Signature Version 4 documentation
+	request, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	require.Nil(t, err, "could not create request")
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	credentials := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	signAWSRequest(request, nil, credentials, "us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	require.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7", request.Header.Get("Authorization"))
+}
+
+func TestUseAWS(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		var input map[string]interface{}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&input))
+		switch r.Header.Get("X-Amz-Target") {
+		case "AmazonSSM.GetParameter":
+			require.Equal(t, "/app/token", input["Name"])
+			_, _ = w.Write([]byte(`{"Parameter": {"Name": "/app/token", "Value": "ssm token"}}`))
+		case "secretsmanager.GetSecretValue":
+			require.Equal(t, "app/credentials", input["SecretId"])
+			_, _ = w.Write([]byte(`{"SecretString": "{\"password\": \"sm password\"}"}`))
+		}
+	}))
+	defer server.Close()
+
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+	credentialsFile := filepath.Join(tempDir, "credentials")
+	err = ioutil.WriteFile(credentialsFile, []byte("[default]\naws_access_key_id = OTHER\naws_secret_access_key = other\n\n[test]\naws_access_key_id = AKID\naws_secret_access_key = secret\n"), os.ModePerm)
+	require.Nil(t, err, "could not write credentials")
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("token: ssm:/app/token\npassword: aws-sm:app/credentials#password\nsame: ssm:/app/token"), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	defer os.Unsetenv("AWS_SHARED_CREDENTIALS_FILE")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.SetConfigFilePath(configFile)
+	flagSet.UseAWS(&AWSOptions{Region: "eu-west-1", Profile: "test", Endpoint: server.URL})
+	var token, password, same string
+	flagSet.StringVar(&token, "token", "", "API token")
+	flagSet.StringVar(&password, "password", "", "Password")
+	flagSet.StringVar(&same, "same", "", "Same token")
+
+	os.Args = []string{os.Args[0]}
+	err = flagSet.Parse()
+	require.Nil(t, err, "could not parse flags")
+	require.Equal(t, "ssm token", token)
+	require.Equal(t, "ssm token", same)
+	require.Equal(t, "sm password", password)
+	require.Equal(t, 2, requests, "could not cache resolved values")
+
+	tearDown(t.Name())
+}
+
+func TestAWSContainerCredentials(t *testing.T) {
+	var requests int
+	status := http.StatusOK
+	expiration := time.Now().Add(time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.Equal(t, "token", r.Header.Get("Authorization"))
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"AccessKeyId":     "AKID",
+			"SecretAccessKey": "secret",
+			"Token":           "session",
+			"Expiration":      expiration,
+		})
+	}))
+	defer server.Close()
+
+	for name, value := range map[string]string{"AWS_CONTAINER_CREDENTIALS_FULL_URI": server.URL, "AWS_CONTAINER_AUTHORIZATION_TOKEN": "token"} {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+	resolver := &awsResolver{options: &AWSOptions{}, client: server.Client()}
+
+	credentials, err := resolver.credentials()
+	require.Nil(t, err, "could not get container credentials")
+	require.Equal(t, "AKID", credentials.AccessKeyID)
+	require.Equal(t, "session", credentials.SessionToken)
+	_, err = resolver.credentials()
+	require.Nil(t, err, "could not get cached container credentials")
+	require.Equal(t, 1, requests, "could not cache container credentials")
+
+	expiration = time.Now().Add(30 * time.Second)
+	resolver.cachedCredentials.Expiration = expiration
+	_, err = resolver.credentials()
+	require.Nil(t, err, "could not renew expiring container credentials")
+	require.Equal(t, 2, requests, "could not renew expiring container credentials")
+
+	status = http.StatusForbidden
+	resolver.cachedCredentials = nil
+	_, err = resolver.credentials()
+	require.EqualError(t, err, "could not get container credentials: unexpected status 403 Forbidden")
+}
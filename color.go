@@ -0,0 +1,64 @@
+package goflags
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences of the usage colors
+const (
+	colorReset   = "\x1b[0m"
+	colorName    = "\x1b[1m"
+	colorType    = "\x1b[36m"
+	colorDefault = "\x1b[33m"
+)
+
+// isTerminal reports whether the output is a terminal, replaced in tests
+var isTerminal = func(output io.Writer) bool {
+	file, ok := output.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+// usageStyle is how the usage of the flags is rendered
+type usageStyle struct {
+	colors bool
+	width  int // width the descriptions are wrapped to, 0 to not wrap them
+	mode   usageMode
+}
+
+// plainOutput reports whether the output gets stable text without the colors,
+// wrapping and pager meant for terminals, for pipes, files and CI logs.
+func (flagSet *FlagSet) plainOutput(output io.Writer) bool {
+	return flagSet.plainRequested() || !isTerminal(output)
+}
+
+// plainRequested reports whether plain output is requested even on a terminal
+func (flagSet *FlagSet) plainRequested() bool {
+	return flagSet.PlainOutput || os.Getenv("TERM") == "dumb"
+}
+
+// usageStyle returns the style of the usage written to the output, colored if
+// ColorUsage is set, the output is a terminal and NO_COLOR is not set, and
+// wrapped to the width of the output if it is a terminal, unless plain output
+// is requested.
+func (flagSet *FlagSet) usageStyle(output io.Writer) usageStyle {
+	if flagSet.plainRequested() {
+		return usageStyle{mode: flagSet.usageMode}
+	}
+	return usageStyle{
+		colors: flagSet.ColorUsage && os.Getenv("NO_COLOR") == "" && isTerminal(output),
+		width:  terminalWidth(output),
+		mode:   flagSet.usageMode,
+	}
+}
+
+// paint returns the text in the color if colors are enabled. Empty texts are
+// painted too, so that the columns of the usage stay aligned.
+func (style usageStyle) paint(color, text string) string {
+	if !style.colors {
+		return text
+	}
+	return color + text + colorReset
+}
@@ -0,0 +1,72 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorUsage(t *testing.T) {
+	defer func(previous func(output io.Writer) bool) { isTerminal = previous }(isTerminal)
+	isTerminal = func(output io.Writer) bool { return true }
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var threads int
+	var verbose bool
+	flagSet.IntVarP(&threads, "threads", "t", 10, "Threads")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.NotContains(t, usage.String(), "\x1b[", "usage was colored without ColorUsage")
+
+	usage.Reset()
+	flagSet.ColorUsage = true
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), colorName+"-t, -threads"+colorReset+colorType+" int"+colorReset)
+	require.Contains(t, usage.String(), "Threads "+colorDefault+"(default 10)"+colorReset+"\n")
+	require.Contains(t, usage.String(), colorName+"-verbose"+colorReset+colorType+colorReset)
+
+	usage.Reset()
+	os.Setenv("NO_COLOR", "1")
+	defer os.Unsetenv("NO_COLOR")
+	flagSet.usageFunc()
+	require.NotContains(t, usage.String(), "\x1b[", "usage was colored with NO_COLOR set")
+}
+
+func TestPlainOutput(t *testing.T) {
+	defer func(previous func(output io.Writer) bool) { isTerminal = previous }(isTerminal)
+	isTerminal = func(output io.Writer) bool { return true }
+	defer func(previous func(output io.Writer) int) { terminalWidth = previous }(terminalWidth)
+	terminalWidth = func(output io.Writer) int { return 40 }
+	defer func(previous func(output io.Writer) int) { terminalHeight = previous }(terminalHeight)
+	terminalHeight = func(output io.Writer) int { return 1 }
+	defer os.Setenv("PAGER", os.Getenv("PAGER"))
+	os.Setenv("PAGER", "tr a-z A-Z")
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.ColorUsage = true
+	flagSet.PagerUsage = true
+	flagSet.PlainOutput = true
+	var threads int
+	flagSet.IntVarP(&threads, "threads", "t", 10, "Number of concurrent threads used to send the requests")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "   -t, -threads int  Number of concurrent threads used to send the requests (default 10)\n")
+
+	usage.Reset()
+	flagSet.PlainOutput = false
+	defer os.Setenv("TERM", os.Getenv("TERM"))
+	os.Setenv("TERM", "dumb")
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "   -t, -threads int  Number of concurrent threads used to send the requests (default 10)\n")
+}
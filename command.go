@@ -0,0 +1,219 @@
+package goflags
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// NewCommand adds a command to the FlagSet, returning the FlagSet of its own flags.
+// Parse then dispatches the arguments following the name of the command to it,
+// the flags of the parent FlagSet being provided before the name of the command.
+// The values of the command flags are read from the section named after the
+// command in the config files of the parent FlagSet, like scan: {threads: 10}.
+func (flagSet *FlagSet) NewCommand(name, description string) *FlagSet {
+	command := NewScopedFlagSet(name, flagSet.CommandLine().ErrorHandling())
+	command.SetDescription(description)
+	command.DisableAutoConfig()
+	command.parent = flagSet
+	command.commandName = name
+	flagSet.commands = append(flagSet.commands, command)
+	return command
+}
+
+// Command returns the command invoked by Parse, or nil if the FlagSet has
+// no commands or none was provided.
+func (flagSet *FlagSet) Command() *FlagSet {
+	return flagSet.invokedCommand
+}
+
+// CommandName returns the name of a FlagSet created with NewCommand
+func (flagSet *FlagSet) CommandName() string {
+	return flagSet.commandName
+}
+
+// commandPath returns the program name followed by the names of the commands leading to the FlagSet
+func (flagSet *FlagSet) commandPath() string {
+	switch {
+	case flagSet.parent != nil:
+		return flagSet.parent.commandPath() + " " + flagSet.commandName
+	case flagSet.applicationName != "":
+		return flagSet.applicationName
+	}
+	return os.Args[0]
+}
+
+// usageLine returns the usage pattern set with SetUsagePattern, or the command
+// path followed by the flags, commands and positional arguments.
+func (flagSet *FlagSet) usageLine() string {
+	switch {
+	case flagSet.usagePattern != "":
+		return flagSet.usagePattern
+	case len(flagSet.commands) > 0:
+		return flagSet.commandPath() + " [flags] <command> [command flags]"
+	}
+	return flagSet.commandPath() + " [flags]" + flagSet.createUsagePositionals()
+}
+
+// AddAlias adds other names the command can be invoked with
+func (flagSet *FlagSet) AddAlias(aliases ...string) {
+	flagSet.aliases = append(flagSet.aliases, aliases...)
+}
+
+// SetDefaultCommand sets the command invoked when the arguments don't name
+// one, so that the flags of a command can be provided without its name.
+func (flagSet *FlagSet) SetDefaultCommand(name string) {
+	flagSet.defaultCommand = name
+}
+
+// lookupCommand returns the command with the name or alias
+func (flagSet *FlagSet) lookupCommand(name string) *FlagSet {
+	for _, command := range flagSet.commands {
+		if command.commandName == name || sliceContains(command.aliases, name) {
+			return command
+		}
+	}
+	return nil
+}
+
+// withDefaultCommand prepends the name of the default command to the
+// arguments if their first positional argument doesn't name a command,
+// unless the only flags before it request help, which lists the commands.
+func (flagSet *FlagSet) withDefaultCommand(args []string) []string {
+	command := flagSet.lookupCommand(flagSet.defaultCommand)
+	if command == nil {
+		return args
+	}
+	command.inheritFlags()
+	onlyHelp := true
+	for i := 0; i < len(args); i++ {
+		arg, ok := parseFlagArg(args[i])
+		if !ok {
+			if args[i] != "--" && flagSet.lookupCommand(args[i]) != nil {
+				return args
+			}
+			break
+		}
+		if _, help := flagSet.requestedHelp(args[i : i+1]); !help {
+			onlyHelp = false
+		}
+		currentFlag := flagSet.CommandLine().Lookup(arg.name)
+		if currentFlag == nil {
+			currentFlag = command.CommandLine().Lookup(arg.name)
+		}
+		if currentFlag != nil && !arg.hasValue && !isBoolFlag(currentFlag) {
+			i++
+		}
+	}
+	if _, help := flagSet.requestedHelp(args); help && onlyHelp {
+		return args
+	}
+	return append([]string{command.commandName}, args...)
+}
+
+// dispatchCommand parses the arguments remaining after the flags with the command they name
+func (flagSet *FlagSet) dispatchCommand() error {
+	flagSet.invokedCommand = nil
+	if len(flagSet.commands) == 0 || flagSet.NArg() == 0 {
+		return nil
+	}
+	command := flagSet.lookupCommand(flagSet.Arg(0))
+	if command == nil {
+		err := &unknownCommandError{name: flagSet.Arg(0)}
+		flagSet.reportUsageError(err)
+		return err
+	}
+	if command.errorHandling == nil {
+		command.errorHandling = flagSet.errorHandling
+		command.exitCode = flagSet.exitCode
+	}
+	if command.onUsageError == nil {
+		command.onUsageError = flagSet.onUsageError
+	}
+	flagSet.invokedCommand = command
+	command.inheritFlags()
+	err := command.ParseArgs(flagSet.Args()[1:])
+	command.CommandLine().Visit(func(fl *flag.Flag) {
+		if owner, ok := command.inherited[fl.Name]; ok {
+			owner.setSource(fl.Name, Source{Kind: SourceCLI})
+		}
+	})
+	return err
+}
+
+// inheritFlags makes the flags of the parent commands settable after the name of
+// the command, unless the command defines flags with the same names.
+func (flagSet *FlagSet) inheritFlags() {
+	for parent := flagSet.parent; parent != nil; parent = parent.parent {
+		parent.flagKeys.forEach(func(key string, data *FlagData) {
+			parentFlag := parent.CommandLine().Lookup(key)
+			if data.skipConfig || parentFlag == nil || flagSet.CommandLine().Lookup(key) != nil {
+				return
+			}
+			flagSet.CommandLine().Var(parentFlag.Value, key, parentFlag.Usage)
+			if flagSet.inherited == nil {
+				flagSet.inherited = make(map[string]*FlagSet)
+			}
+			flagSet.inherited[key] = parent
+		})
+	}
+}
+
+// unknownCommandError is returned by Parse when the arguments name an unknown command
+type unknownCommandError struct {
+	name string
+}
+
+func (err *unknownCommandError) Error() string {
+	return fmt.Sprintf("unknown command %q", err.name)
+}
+
+// writeUsageCommands writes the names and descriptions of the commands
+func (flagSet *FlagSet) writeUsageCommands(writer *tabwriter.Writer) {
+	for _, command := range flagSet.commands {
+		names := strings.Join(append([]string{command.commandName}, command.aliases...), ", ")
+		description := command.description
+		if command.commandName == flagSet.defaultCommand {
+			description += " (default)"
+		}
+		fmt.Fprintf(writer, "   %s\t%s\n", names, description)
+	}
+}
+
+// configSection is the section of a config file holding the values of a command
+type configSection struct {
+	data   map[string]interface{}
+	source string
+	lines  map[string]int
+}
+
+// recordConfigSections records the sections of the config data named after the
+// commands, which are merged only if the command is invoked.
+func (flagSet *FlagSet) recordConfigSections(data map[string]interface{}, source string, lines map[string]int) {
+	for _, command := range flagSet.commands {
+		section, ok := configMap(data[command.commandName])
+		if !ok {
+			continue
+		}
+		prefix := command.commandName + "."
+		sectionLines := make(map[string]int)
+		for key, line := range lines {
+			if strings.HasPrefix(key, prefix) {
+				sectionLines[strings.TrimPrefix(key, prefix)] = line
+			}
+		}
+		command.configSections = append(command.configSections, configSection{data: section, source: source, lines: sectionLines})
+	}
+}
+
+// mergeConfigSections merges the sections of the config files of the parent
+// command holding the values of the command.
+func (flagSet *FlagSet) mergeConfigSections() error {
+	var errs Errors
+	for _, section := range flagSet.configSections {
+		errs.add(flagSet.mergeConfigData(section.data, section.source, section.lines))
+	}
+	return errs.err()
+}
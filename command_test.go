@@ -0,0 +1,160 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommands(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.SetDescription("Scanner")
+	var verbose, json bool
+	var threads int
+	var output string
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+
+	scan := flagSet.NewCommand("scan", "Scan targets")
+	scan.IntVarP(&threads, "threads", "t", 10, "Threads")
+	report := flagSet.NewCommand("report", "Generate a report")
+	report.StringVarP(&output, "output", "o", "", "Output file").Required()
+	report.BoolVar(&json, "json", false, "JSON output")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), " [flags] <command> [command flags]\n\nCommands:\n   scan    Scan targets\n   report  Generate a report\n")
+
+	usage.Reset()
+	scan.CommandLine().SetOutput(usage)
+	scan.usageFunc()
+	require.Contains(t, usage.String(), "Scan targets\n\nUsage:\n")
+	require.Contains(t, usage.String(), " scan [flags]\n")
+	require.Contains(t, usage.String(), "-t, -threads int")
+
+	require.Nil(t, flagSet.ParseArgs([]string{"-verbose", "scan", "-t", "20"}), "could not parse command")
+	require.True(t, verbose)
+	require.Equal(t, 20, threads)
+	require.Equal(t, scan, flagSet.Command())
+	require.Equal(t, "scan", flagSet.Command().CommandName())
+
+	err := flagSet.ParseArgs([]string{"report", "-json"})
+	require.NotNil(t, err, "could parse command without required flag")
+	require.Equal(t, "missing required flags: -output", err.Error())
+
+	err = flagSet.ParseArgs([]string{"unknown"})
+	require.NotNil(t, err, "could parse unknown command")
+	require.Equal(t, "unknown command \"unknown\"", err.Error())
+
+	require.Nil(t, flagSet.ParseArgs(nil), "could not parse without command")
+	require.Nil(t, flagSet.Command())
+}
+
+func TestNestedCommands(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var verbose, global bool
+	var key, value string
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+
+	config := flagSet.NewCommand("config", "Manage the configuration")
+	config.BoolVar(&global, "global", false, "Use the global configuration")
+	set := config.NewCommand("set", "Set a configuration value")
+	set.AddPositional(&key, "key", "Key to set", true)
+	set.AddPositional(&value, "value", "Value to set", true)
+
+	require.Nil(t, flagSet.ParseArgs([]string{"config", "set", "-verbose", "-global", "threads", "10"}), "could not parse nested command")
+	require.True(t, verbose, "could not inherit root flag")
+	require.True(t, global, "could not inherit parent flag")
+	require.Equal(t, "threads", key)
+	require.Equal(t, "10", value)
+	require.Equal(t, set, flagSet.Command().Command())
+	require.Equal(t, SourceCLI, flagSet.Source("verbose").Kind)
+
+	usage := &bytes.Buffer{}
+	set.CommandLine().SetOutput(usage)
+	set.usageFunc()
+	require.Contains(t, usage.String(), " config set [flags] <key> <value>\n")
+	require.Contains(t, usage.String(), " config:\n   -global  Use the global configuration\n")
+	require.Contains(t, usage.String(), ":\n   -verbose  Verbose output\n")
+}
+
+func TestCommandAliases(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var verbose, all bool
+	var target string
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+
+	list := flagSet.NewCommand("list", "List templates")
+	list.AddAlias("ls")
+	list.BoolVar(&all, "all", false, "List all templates")
+	scan := flagSet.NewCommand("scan", "Scan targets")
+	scan.StringVarP(&target, "target", "u", "", "Target")
+	flagSet.SetDefaultCommand("scan")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "Commands:\n   list, ls  List templates\n   scan      Scan targets (default)\n")
+
+	require.Nil(t, flagSet.ParseArgs([]string{"ls", "-all"}), "could not parse command alias")
+	require.Equal(t, list, flagSet.Command())
+	require.True(t, all)
+
+	require.Nil(t, flagSet.ParseArgs([]string{"-verbose", "-u", "example.com"}), "could not parse default command")
+	require.Equal(t, scan, flagSet.Command())
+	require.Equal(t, "example.com", target)
+	require.True(t, verbose)
+
+	require.Nil(t, flagSet.ParseArgs(nil), "could not parse without arguments")
+	require.Equal(t, scan, flagSet.Command())
+
+	require.Nil(t, flagSet.ParseArgs([]string{"-verbose", "list"}), "could not parse command after flags")
+	require.Equal(t, list, flagSet.Command())
+
+	usage.Reset()
+	require.Equal(t, flag.ErrHelp, flagSet.ParseArgs([]string{"-h"}), "could not request help")
+	require.Contains(t, usage.String(), "Commands:\n   list, ls  List templates\n   scan      Scan targets (default)\n", "help did not list the commands")
+}
+
+func TestCommandConfigSections(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("verbose: true\nscan:\n  threads: 50\n  rate: fast\nreport:\n  output: report.txt\n"), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath(configFile)
+	flagSet.StrictConfig = true
+	var verbose bool
+	var threads int
+	var output string
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+	scan := flagSet.NewCommand("scan", "Scan targets")
+	scan.IntVarP(&threads, "threads", "t", 10, "Threads")
+	scan.StrictConfig = true
+	report := flagSet.NewCommand("report", "Generate a report")
+	report.StringVarP(&output, "output", "o", "", "Output file")
+
+	err = flagSet.ParseArgs([]string{"scan"})
+	require.NotNil(t, err, "could merge unknown key of command section")
+	require.Equal(t, configFile+":4: invalid value for \"rate\": unknown flag", err.Error())
+	require.True(t, verbose)
+	require.Equal(t, 50, threads)
+	require.Empty(t, output, "could merge section of command not invoked")
+
+	scan.StrictConfig = false
+	threads = 10
+	require.Nil(t, flagSet.ParseArgs([]string{"scan", "-t", "20"}), "could not parse command")
+	require.Equal(t, 20, threads, "could not prefer command line over config section")
+}
@@ -0,0 +1,136 @@
+package goflags
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// completeCommandName is the hidden command printing the completions of the
+// last argument, invoked by the completion scripts.
+const completeCommandName = "__complete"
+
+// ErrCompletion is returned by Parse when completions were requested with __complete
+var ErrCompletion = errors.New("completion requested")
+
+// completionOutput is where the completions are printed, replaced in tests
+var completionOutput io.Writer = os.Stdout
+
+// CompleteWith sets the function returning the values completing the value of
+// the flag being typed, so that values like template names can be completed from
+// live data. The function is called by the completion scripts through the
+// hidden __complete command.
+func (flagData *FlagData) CompleteWith(complete func(toComplete string) []string) *FlagData {
+	flagData.completer = complete
+	return flagData
+}
+
+// printCompletions prints the completions of the last of the arguments, one per
+// line, the arguments being the ones provided after __complete.
+func (flagSet *FlagSet) printCompletions(args []string) {
+	for _, completion := range flagSet.completions(args) {
+		fmt.Fprintln(completionOutput, completion)
+	}
+}
+
+// completions returns the completions of the last of the arguments, completing
+// the value of a flag, a flag name or a command name.
+func (flagSet *FlagSet) completions(args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	target, valueFlag := flagSet, (*FlagData)(nil)
+	for _, arg := range args[:len(args)-1] {
+		if valueFlag != nil {
+			valueFlag = nil
+			continue
+		}
+		if parsed, ok := parseFlagArg(arg); ok {
+			if data, ok := target.flagKeys.values[parsed.name]; ok && !parsed.hasValue && target.takesValue(data) {
+				valueFlag = data
+			}
+			continue
+		}
+		if command := target.lookupCommand(arg); command != nil {
+			target = command
+		}
+	}
+
+	toComplete, prefix := args[len(args)-1], ""
+	if parsed, ok := parseFlagArg(toComplete); ok && parsed.hasValue && valueFlag == nil {
+		valueFlag = target.flagKeys.values[parsed.name]
+		toComplete, prefix = parsed.value, parsed.dashes+parsed.name+"="
+	}
+
+	var completions []string
+	switch {
+	case valueFlag != nil:
+		for _, value := range target.completeValue(valueFlag, toComplete) {
+			completions = append(completions, prefix+value)
+		}
+	case strings.HasPrefix(toComplete, "-"):
+		for _, data := range target.completionFlags() {
+			for _, name := range target.completionNames(data) {
+				if strings.HasPrefix(name, toComplete) {
+					completions = append(completions, name)
+				}
+			}
+		}
+	default:
+		for _, command := range target.commands {
+			for _, name := range append([]string{command.commandName}, command.aliases...) {
+				if strings.HasPrefix(name, toComplete) {
+					completions = append(completions, name)
+				}
+			}
+		}
+	}
+	return completions
+}
+
+// completeValue returns the values completing the value of the flag, the allowed
+// values starting with the value being typed for enum flags.
+func (flagSet *FlagSet) completeValue(data *FlagData, toComplete string) []string {
+	switch {
+	case data.completer != nil:
+		return data.completer(toComplete)
+	case data.enum != nil:
+		return flagSet.completeEnum(data, toComplete)
+	}
+	return nil
+}
+
+// completeEnum returns the allowed values of the enum flag starting with the value
+// being typed, completing the last of the comma separated values of enum slices.
+func (flagSet *FlagSet) completeEnum(data *FlagData, toComplete string) []string {
+	var selected string
+	if _, repeatable := flagSet.completionValue(data); repeatable {
+		if index := strings.LastIndex(toComplete, ","); index >= 0 {
+			selected, toComplete = toComplete[:index+1], toComplete[index+1:]
+		}
+	}
+	var completions []string
+	for _, allowed := range data.enum.allowed {
+		if len(allowed) >= len(toComplete) && data.enum.matches(allowed[:len(toComplete)], toComplete) {
+			completions = append(completions, selected+allowed)
+		}
+	}
+	return completions
+}
+
+// takesValue reports whether the flag is followed by a value on the command line
+func (flagSet *FlagSet) takesValue(data *FlagData) bool {
+	typeName, _ := flagSet.completionValue(data)
+	return typeName != ""
+}
+
+// completionPath returns the names of the commands leading to the FlagSet
+func (flagSet *FlagSet) completionPath() []string {
+	if flagSet.parent == nil {
+		return nil
+	}
+	return append(flagSet.parent.completionPath(), flagSet.commandName)
+}
@@ -0,0 +1,94 @@
+package goflags
+
+import (
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CompletionShell is a shell GenerateCompletion writes a completion script for
+type CompletionShell string
+
+const (
+	// CompletionZsh writes a zsh completion script using _arguments
+	CompletionZsh CompletionShell = "zsh"
+	// CompletionPowerShell writes a PowerShell completion script using Register-ArgumentCompleter
+	CompletionPowerShell CompletionShell = "powershell"
+)
+
+// CompleteFiles makes shell completion suggest file paths as values of the flag
+func (flagData *FlagData) CompleteFiles() *FlagData {
+	flagData.completeFiles = true
+	return flagData
+}
+
+// GenerateCompletion writes a completion script of the program for the shell,
+// completing the flags shown in the usage with their descriptions, the allowed
+// values of enum flags, file paths for flags marked with CompleteFiles, the values
+// returned by the functions set with CompleteWith, and the commands with their
+// own flags.
+func (flagSet *FlagSet) GenerateCompletion(w io.Writer, shell CompletionShell) error {
+	var builder strings.Builder
+	switch shell {
+	case CompletionZsh:
+		flagSet.writeZshCompletion(&builder)
+	case CompletionPowerShell:
+		flagSet.writePowerShellCompletion(&builder)
+	default:
+		return errors.Errorf("unsupported completion shell %q", shell)
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// completionFlags returns the flags completed by the shell, the ones shown in the usage
+func (flagSet *FlagSet) completionFlags() []*FlagData {
+	return flagSet.usageFlags(func(data *FlagData) bool { return !data.configOnly && !data.hidden }, nil)
+}
+
+// completionNames returns the names of the flag as completed by the shell, the short one first
+func (flagSet *FlagSet) completionNames(data *FlagData) []string {
+	longPrefix := "-"
+	if flagSet.DoubleDashUsage {
+		longPrefix = "--"
+	}
+	var names []string
+	if data.short != "" {
+		names = append(names, "-"+data.short)
+	}
+	if data.long != "" {
+		names = append(names, longPrefix+data.long)
+	}
+	return names
+}
+
+// completionValue returns the name of the type of the value of the flag, empty
+// for bool flags, and whether the flag can be provided more than once.
+func (flagSet *FlagSet) completionValue(data *FlagData) (string, bool) {
+	currentFlag := *flagSet.CommandLine().Lookup(data.name())
+	currentFlag.Value = unwrapValue(currentFlag.Value)
+	if isBoolFlag(&currentFlag) {
+		return "", false
+	}
+	typeName, _ := usageTypeName(&currentFlag, reflect.TypeOf(currentFlag.Value))
+	return typeName, strings.HasSuffix(typeName, "[]")
+}
+
+// completionDescription returns the first line of the usage of the flag
+func completionDescription(data *FlagData) string {
+	return strings.SplitN(data.usage, "\n", 2)[0]
+}
+
+// completionFunctionRegex matches the characters which can't be used in the name of a shell function
+var completionFunctionRegex = regexp.MustCompile(`[^\w]`)
+
+// completionFunction returns the name of the shell function completing the FlagSet
+func (flagSet *FlagSet) completionFunction() string {
+	if flagSet.parent != nil {
+		return flagSet.parent.completionFunction() + "_" + completionFunctionRegex.ReplaceAllString(flagSet.commandName, "_")
+	}
+	return "_" + completionFunctionRegex.ReplaceAllString(flagSet.appName(), "_")
+}
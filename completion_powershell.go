@@ -0,0 +1,126 @@
+package goflags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// powerShellCompleter is the script block of the PowerShell completer, completing
+// the values of the flags, the flags and the commands of the $completions table.
+const powerShellCompleter = `
+    $path = ''
+    $valueFlag = $null
+    foreach ($element in $commandAst.CommandElements | Select-Object -Skip 1) {
+        if ($element.Extent.EndOffset -ge $cursorPosition) { break }
+        $text = $element.ToString()
+        if ($valueFlag) {
+            $valueFlag = $null
+            continue
+        }
+        if ($text.StartsWith('-')) {
+            $key = $text.TrimStart('-')
+            $valueFlag = $completions[$path].Flags | Where-Object { $_.Value -and $_.Identifiers -contains $key } | Select-Object -First 1
+            continue
+        }
+        $command = $completions[$path].Commands | Where-Object { $_.Names -contains $text } | Select-Object -First 1
+        if ($command) { $path = ($path + ' ' + $command.Names[0]).Trim() }
+    }
+
+    $prefix = ''
+    if (-not $valueFlag -and $wordToComplete -match '^(-[^=]+=)(.*)$') {
+        $prefix = $Matches[1]
+        $key = $prefix.TrimStart('-').TrimEnd('=')
+        $valueFlag = $completions[$path].Flags | Where-Object { $_.Value -and $_.Identifiers -contains $key } | Select-Object -First 1
+        $wordToComplete = $Matches[2]
+    }
+    if ($valueFlag) {
+        $choices = $valueFlag.Choices | Where-Object { $_ -like "$wordToComplete*" }
+        if ($valueFlag.Dynamic) {
+            $program = $commandAst.CommandElements[0].ToString()
+            $argument = $wordToComplete
+            if (-not $argument -and ($PSVersionTable.PSVersion.Major -lt 7 -or -not $PSNativeCommandArgumentPassing -or $PSNativeCommandArgumentPassing -eq 'Legacy')) {
+                $argument = '""'
+            }
+            $choices = & $program __complete @($path -split ' ' | Where-Object { $_ }) $valueFlag.Names[-1] $argument 2>$null
+        }
+        $choices | Where-Object { $_ } | ForEach-Object {
+            [System.Management.Automation.CompletionResult]::new($prefix + $_, $_, 'ParameterValue', $_)
+        }
+        return
+    }
+    if ($wordToComplete.StartsWith('-')) {
+        foreach ($flag in $completions[$path].Flags) {
+            $flag.Names | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+                [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterName', $flag.Description)
+            }
+        }
+        return
+    }
+    foreach ($command in $completions[$path].Commands) {
+        $command.Names | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+            [System.Management.Automation.CompletionResult]::new($_, $_, 'Command', $command.Description)
+        }
+    }
+`
+
+// powerShellQuote returns the text quoted for PowerShell with single quotes
+func powerShellQuote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
+
+// powerShellList returns the texts as a PowerShell array of quoted strings
+func powerShellList(texts []string) string {
+	quoted := make([]string, len(texts))
+	for i, text := range texts {
+		quoted[i] = powerShellQuote(text)
+	}
+	return "@(" + strings.Join(quoted, ", ") + ")"
+}
+
+// writePowerShellCompletion writes the PowerShell completion script of the program
+func (flagSet *FlagSet) writePowerShellCompletion(builder *strings.Builder) {
+	fmt.Fprintf(builder, "Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n", powerShellQuote(flagSet.appName()))
+	builder.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n\n    $completions = @{\n")
+	flagSet.writePowerShellCompletions(builder, "")
+	builder.WriteString("    }\n")
+	builder.WriteString(powerShellCompleter)
+	builder.WriteString("}\n")
+}
+
+// writePowerShellCompletions writes the entry of the $completions table of the FlagSet,
+// keyed by the names of the commands leading to it, followed by the ones of its commands.
+func (flagSet *FlagSet) writePowerShellCompletions(builder *strings.Builder, path string) {
+	fmt.Fprintf(builder, "        %s = @{\n            Flags = @(\n", powerShellQuote(path))
+	for _, data := range flagSet.completionFlags() {
+		typeName, _ := flagSet.completionValue(data)
+		identifiers := []string{data.short, data.long}
+		if data.short == "" || data.long == "" {
+			identifiers = []string{data.name()}
+		}
+		var choices []string
+		if data.enum != nil {
+			choices = data.enum.allowed
+		}
+		names := flagSet.completionNames(data)
+		fmt.Fprintf(builder, "                @{ Names = %s; Identifiers = %s; Description = %s; Value = $%t; Choices = %s; Dynamic = $%t }\n",
+			powerShellList(names), powerShellList(identifiers), powerShellQuote(completionTooltip(completionDescription(data), names[0])), typeName != "", powerShellList(choices), data.completer != nil)
+	}
+	builder.WriteString("            )\n            Commands = @(\n")
+	for _, command := range flagSet.commands {
+		names := append([]string{command.commandName}, command.aliases...)
+		fmt.Fprintf(builder, "                @{ Names = %s; Description = %s }\n", powerShellList(names), powerShellQuote(completionTooltip(command.description, command.commandName)))
+	}
+	builder.WriteString("            )\n        }\n")
+	for _, command := range flagSet.commands {
+		command.writePowerShellCompletions(builder, strings.TrimSpace(path+" "+command.commandName))
+	}
+}
+
+// completionTooltip returns the description, or the name if it is empty as
+// PowerShell rejects empty tooltips.
+func completionTooltip(description, name string) string {
+	if description == "" {
+		return name
+	}
+	return description
+}
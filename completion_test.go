@@ -0,0 +1,138 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newCompletionFlagSet returns a FlagSet with flags of every kind of completion and a command
+func newCompletionFlagSet(t *testing.T) *FlagSet {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.SetAppName("scanner")
+	var output, mode, debugKey string
+	var targets StringSlice
+	var verbose bool
+	var threads int
+	flagSet.StringSliceVarP(&targets, "target", "u", nil, "Targets to scan [host]")
+	flagSet.StringVarP(&output, "output", "o", "", "Output file").CompleteFiles()
+	flagSet.EnumVar(&mode, "mode", "fast", []string{"fast", "slow"}, "Scan mode")
+	flagSet.BoolVar(&verbose, "verbose", false, "Don't be quiet")
+	flagSet.StringVar(&debugKey, "debug-key", "", "Debug key").Hidden()
+	scan := flagSet.NewCommand("scan", "Scan the targets")
+	scan.AddAlias("s")
+	scan.IntVar(&threads, "threads", 10, "Threads")
+	return flagSet
+}
+
+func TestZshCompletion(t *testing.T) {
+	flagSet := newCompletionFlagSet(t)
+
+	script := &bytes.Buffer{}
+	err := flagSet.GenerateCompletion(script, CompletionZsh)
+	require.Nil(t, err, "could not generate completion")
+	require.Contains(t, script.String(), "#compdef scanner\n\n_scanner() {\n  local state\n  _arguments \\\n"+
+		`    '*'{-u,-target}'=[Targets to scan \[host\]]:string[]:' \`+"\n"+
+		`    '(-o -output)'{-o,-output}'=[Output file]:string:_files' \`+"\n"+
+		`    -mode'=[Scan mode]:string:(fast slow)' \`+"\n"+
+		`    -verbose'[Don'\''t be quiet]' \`+"\n"+
+		`    '1:command:((scan\:"Scan the targets" s\:"Scan the targets"))' \`+"\n")
+	require.Contains(t, script.String(), "        scan|s) _scanner_scan ;;\n")
+	require.Contains(t, script.String(), "_scanner_scan() {\n  _arguments \\\n    -threads'=[Threads]:int:'\n}\n")
+	require.NotContains(t, script.String(), "debug-key")
+
+	err = flagSet.GenerateCompletion(script, "tcsh")
+	require.EqualError(t, err, `unsupported completion shell "tcsh"`)
+}
+
+func TestPowerShellCompletion(t *testing.T) {
+	flagSet := newCompletionFlagSet(t)
+
+	script := &bytes.Buffer{}
+	err := flagSet.GenerateCompletion(script, CompletionPowerShell)
+	require.Nil(t, err, "could not generate completion")
+	require.True(t, strings.HasPrefix(script.String(), "Register-ArgumentCompleter -Native -CommandName 'scanner' -ScriptBlock {\n"), script.String())
+	require.Contains(t, script.String(), "        '' = @{\n            Flags = @(\n"+
+		"                @{ Names = @('-u', '-target'); Identifiers = @('u', 'target'); Description = 'Targets to scan [host]'; Value = $true; Choices = @(); Dynamic = $false }\n"+
+		"                @{ Names = @('-o', '-output'); Identifiers = @('o', 'output'); Description = 'Output file'; Value = $true; Choices = @(); Dynamic = $false }\n"+
+		"                @{ Names = @('-mode'); Identifiers = @('mode'); Description = 'Scan mode'; Value = $true; Choices = @('fast', 'slow'); Dynamic = $false }\n"+
+		"                @{ Names = @('-verbose'); Identifiers = @('verbose'); Description = 'Don''t be quiet'; Value = $false; Choices = @(); Dynamic = $false }\n"+
+		"            )\n            Commands = @(\n"+
+		"                @{ Names = @('scan', 's'); Description = 'Scan the targets' }\n")
+	require.Contains(t, script.String(), "        'scan' = @{\n            Flags = @(\n"+
+		"                @{ Names = @('-threads'); Identifiers = @('threads'); Description = 'Threads'; Value = $true; Choices = @(); Dynamic = $false }\n")
+	require.NotContains(t, script.String(), "debug-key")
+}
+
+func TestCompleteWith(t *testing.T) {
+	defer func(previous io.Writer) { completionOutput = previous }(completionOutput)
+	output := &bytes.Buffer{}
+	completionOutput = output
+
+	flagSet := newCompletionFlagSet(t)
+	var templates StringSlice
+	scan := flagSet.lookupCommand("scan")
+	scan.StringSliceVarP(&templates, "templates", "t", nil, "Templates to run").CompleteWith(func(toComplete string) []string {
+		var completions []string
+		for _, template := range []string{"cves", "cloud", "dns"} {
+			if strings.HasPrefix(template, toComplete) {
+				completions = append(completions, template)
+			}
+		}
+		return completions
+	})
+
+	complete := func(args ...string) []string {
+		output.Reset()
+		err := flagSet.ParseArgs(append([]string{"__complete"}, args...))
+		require.Equal(t, ErrCompletion, err)
+		return strings.Fields(output.String())
+	}
+	require.Equal(t, []string{"cves", "cloud"}, complete("s", "-templates", "c"))
+	require.Equal(t, []string{"-t=cves"}, complete("scan", "-threads", "5", "-t=cv"))
+	require.Equal(t, []string{"-threads", "-t", "-templates"}, complete("scan", "-t"))
+	require.Equal(t, []string{"-o", "-output"}, complete("-o"))
+	require.Equal(t, []string{"scan", "s"}, complete(""))
+	require.Empty(t, complete("-output", ""))
+
+	script := &bytes.Buffer{}
+	require.Nil(t, flagSet.GenerateCompletion(script, CompletionZsh), "could not generate completion")
+	require.Contains(t, script.String(), `'*'{-t,-templates}'=[Templates to run]:string[]:{compadd -- ${(f)"$($service __complete scan -templates "$PREFIX" 2>/dev/null)"}}'`)
+	script.Reset()
+	require.Nil(t, flagSet.GenerateCompletion(script, CompletionPowerShell), "could not generate completion")
+	require.Contains(t, script.String(), "Description = 'Templates to run'; Value = $true; Choices = @(); Dynamic = $true }\n")
+}
+
+func TestEnumCompletion(t *testing.T) {
+	defer func(previous io.Writer) { completionOutput = previous }(completionOutput)
+	output := &bytes.Buffer{}
+	completionOutput = output
+
+	flagSet := newCompletionFlagSet(t)
+	var protocols []string
+	var severity string
+	flagSet.EnumSliceVar(&protocols, "protocols", nil, []string{"http", "https", "dns"}, "Protocols to scan")
+	flagSet.EnumVar(&severity, "severity", "low", []string{"low", "Medium", "high"}, "Severity").CaseInsensitive()
+
+	complete := func(args ...string) []string {
+		output.Reset()
+		err := flagSet.ParseArgs(append([]string{"__complete"}, args...))
+		require.Equal(t, ErrCompletion, err)
+		return strings.Fields(output.String())
+	}
+	require.Equal(t, []string{"fast", "slow"}, complete("-mode", ""))
+	require.Equal(t, []string{"slow"}, complete("-mode", "s"))
+	require.Equal(t, []string{"-mode=fast"}, complete("-mode=f"))
+	require.Equal(t, []string{"http", "https"}, complete("-protocols", "ht"))
+	require.Equal(t, []string{"dns,http", "dns,https"}, complete("-protocols", "dns,h"))
+	require.Equal(t, []string{"Medium"}, complete("-severity", "me"))
+
+	script := &bytes.Buffer{}
+	require.Nil(t, flagSet.GenerateCompletion(script, CompletionZsh), "could not generate completion")
+	require.Contains(t, script.String(), `'*'-protocols'=[Protocols to scan]:string[]:_values -s , protocols http https dns'`)
+}
@@ -0,0 +1,107 @@
+package goflags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// zshQuote returns the text quoted for zsh with single quotes
+func zshQuote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", `'\''`) + "'"
+}
+
+// zshDescriptionReplacer escapes the characters closing the description of an _arguments spec
+var zshDescriptionReplacer = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+// zshValueReplacer escapes the characters separating the values of an _arguments action
+var zshValueReplacer = strings.NewReplacer(`\`, `\\`, " ", `\ `, ":", `\:`, "(", `\(`, ")", `\)`, `"`, `\"`)
+
+// writeZshCompletion writes the zsh completion script of the program
+func (flagSet *FlagSet) writeZshCompletion(builder *strings.Builder) {
+	function := flagSet.completionFunction()
+	fmt.Fprintf(builder, "#compdef %s\n", flagSet.appName())
+	flagSet.writeZshFunction(builder)
+	fmt.Fprintf(builder, "\nif [ \"$funcstack[1]\" = %q ]; then\n  %s \"$@\"\nelse\n  compdef %s %s\nfi\n", function, function, function, flagSet.appName())
+}
+
+// writeZshFunction writes the zsh function completing the FlagSet, followed by
+// the ones of its commands.
+func (flagSet *FlagSet) writeZshFunction(builder *strings.Builder) {
+	fmt.Fprintf(builder, "\n%s() {\n", flagSet.completionFunction())
+	if len(flagSet.commands) > 0 {
+		builder.WriteString("  local state\n")
+	}
+	builder.WriteString("  _arguments")
+	for _, data := range flagSet.completionFlags() {
+		fmt.Fprintf(builder, " \\\n    %s", flagSet.zshFlagSpec(data))
+	}
+	if len(flagSet.commands) == 0 {
+		builder.WriteString("\n}\n")
+		return
+	}
+
+	var commands []string
+	for _, command := range flagSet.commands {
+		for _, name := range append([]string{command.commandName}, command.aliases...) {
+			commands = append(commands, zshValueReplacer.Replace(name)+`\:"`+strings.ReplaceAll(command.description, `"`, `\"`)+`"`)
+		}
+	}
+	fmt.Fprintf(builder, " \\\n    %s \\\n    '*::arg:->args'\n", zshQuote("1:command:(("+strings.Join(commands, " ")+"))"))
+	builder.WriteString("  case $state in\n    args)\n      case $words[1] in\n")
+	for _, command := range flagSet.commands {
+		names := strings.Join(append([]string{command.commandName}, command.aliases...), "|")
+		fmt.Fprintf(builder, "        %s) %s ;;\n", names, command.completionFunction())
+	}
+	builder.WriteString("      esac\n      ;;\n  esac\n}\n")
+	for _, command := range flagSet.commands {
+		command.writeZshFunction(builder)
+	}
+}
+
+// zshFlagSpec returns the _arguments spec of the flag, with its description and
+// the completion of its value.
+func (flagSet *FlagSet) zshFlagSpec(data *FlagData) string {
+	names := flagSet.completionNames(data)
+	typeName, repeatable := flagSet.completionValue(data)
+
+	var prefix string
+	switch {
+	case repeatable:
+		prefix = zshQuote("*")
+	case len(names) > 1:
+		prefix = zshQuote("(" + strings.Join(names, " ") + ")")
+	}
+	if len(names) > 1 {
+		prefix += "{" + strings.Join(names, ",") + "}"
+	} else {
+		prefix += names[0]
+	}
+
+	spec := "[" + zshDescriptionReplacer.Replace(completionDescription(data)) + "]"
+	if typeName != "" {
+		spec = "=" + spec + ":" + strings.ReplaceAll(typeName, ":", `\:`) + ":" + flagSet.zshValueAction(data)
+	}
+	return prefix + zshQuote(spec)
+}
+
+// zshValueAction returns the _arguments action completing the value of the flag
+func (flagSet *FlagSet) zshValueAction(data *FlagData) string {
+	switch {
+	case data.completer != nil:
+		names := flagSet.completionNames(data)
+		args := append(append([]string{completeCommandName}, flagSet.completionPath()...), names[len(names)-1])
+		return `{compadd -- ${(f)"$($service ` + strings.Join(args, " ") + ` "$PREFIX" 2>/dev/null)"}}`
+	case data.enum != nil:
+		values := make([]string, len(data.enum.allowed))
+		for i, value := range data.enum.allowed {
+			values[i] = zshValueReplacer.Replace(value)
+		}
+		if _, repeatable := flagSet.completionValue(data); repeatable {
+			return "_values -s , " + data.name() + " " + strings.Join(values, " ")
+		}
+		return "(" + strings.Join(values, " ") + ")"
+	case data.completeFiles:
+		return "_files"
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package goflags
+
+import "github.com/pkg/errors"
+
+// AddFlagSet adds the flags of another FlagSet, with their options, their groups
+// and the constraints between them, so that bundles of flags can be shared between
+// programs. The flags keep the fields they were registered with.
+//
+// NOTE: AddFlagSet panics if a flag of the other FlagSet has the name of a flag
+// already defined, unless the FlagSet is in library mode.
+func (flagSet *FlagSet) AddFlagSet(other *FlagSet) {
+	var errs Errors
+	other.flagKeys.forEach(func(key string, data *FlagData) {
+		if isBuiltinFlag(key) {
+			return
+		}
+		if _, ok := flagSet.flagKeys.values[key]; ok || flagSet.CommandLine().Lookup(key) != nil {
+			errs.add(errors.Errorf("flag redefined: %s", key))
+		}
+		errs.add(flagSet.checkFrozen(key))
+	})
+	if len(errs) > 0 {
+		flagSet.fail(errs)
+		return
+	}
+
+	added := make(map[*FlagData]*flagValue)
+	other.flagKeys.forEach(func(key string, data *FlagData) {
+		currentFlag := other.CommandLine().Lookup(key)
+		if currentFlag == nil || isBuiltinFlag(key) {
+			return
+		}
+		wrapped, ok := added[data]
+		if !ok {
+			copied := *data
+			copied.flagSet = flagSet
+			wrapped = &flagValue{Value: unwrapValue(currentFlag.Value), flagSet: flagSet, data: &copied}
+			added[data] = wrapped
+		}
+		flagSet.CommandLine().Var(wrapped, key, currentFlag.Usage)
+		flagSet.flagKeys.Set(key, wrapped.data)
+	})
+	for _, group := range other.groups {
+		if flagSet.findGroup(group.name) == nil {
+			flagSet.groups = append(flagSet.groups, group)
+		}
+	}
+	flagSet.requiredTogether = append(flagSet.requiredTogether, other.requiredTogether...)
+	flagSet.oneRequired = append(flagSet.oneRequired, other.oneRequired...)
+	flagSet.requirements = append(flagSet.requirements, other.requirements...)
+}
@@ -0,0 +1,57 @@
+package goflags
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddFlagSet(t *testing.T) {
+	var proxy string
+	var timeout int
+	network := NewScopedFlagSet("network", flag.ContinueOnError)
+	network.CreateGroup("network", "Network",
+		network.StringVarP(&proxy, "proxy", "p", "", "Proxy to use"),
+		network.IntVar(&timeout, "timeout", 10, "Timeout in seconds").WithMin(1),
+	)
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var output string
+	flagSet.StringVar(&output, "output", "", "Output file")
+	flagSet.AddFlagSet(network)
+
+	err := flagSet.ParseArgs([]string{"-output", "out.txt", "-p", "http://127.0.0.1:8080", "-timeout", "5"})
+	require.Nil(t, err, "could not parse flags")
+	require.Equal(t, "out.txt", output)
+	require.Equal(t, "http://127.0.0.1:8080", proxy)
+	require.Equal(t, 5, timeout)
+	require.True(t, flagSet.Changed("proxy"))
+	flagSet.VisitAll(func(info *FlagInfo) {
+		if info.Name == "proxy" {
+			require.Equal(t, "network", info.Group, "group of the added flag was not kept")
+		}
+	})
+
+	err = flagSet.ParseArgs([]string{"-timeout", "0"})
+	require.NotNil(t, err, "bounds of the added flag were not checked")
+
+	t.Run("collision", func(t *testing.T) {
+		flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+		var other string
+		flagSet.StringVar(&other, "proxy", "", "Other proxy")
+		require.PanicsWithValue(t, "flag redefined: proxy", func() {
+			flagSet.AddFlagSet(network)
+		})
+		require.Nil(t, flagSet.CommandLine().Lookup("timeout"), "flags were added despite the collision")
+
+		flagSet = NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+		flagSet.SetConfigFilePath("")
+		flagSet.LibraryMode = true
+		flagSet.StringVar(&other, "timeout", "", "Other timeout")
+		flagSet.AddFlagSet(network)
+		err := flagSet.ParseArgs(nil)
+		require.EqualError(t, err, "flag redefined: timeout")
+	})
+}
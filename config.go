@@ -0,0 +1,287 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const (
+	// configFlagName is the name of the built-in flag loading an explicit config file
+	configFlagName = "config"
+	// writeConfigFlagName is the name of the built-in flag writing the resolved config
+	writeConfigFlagName = "write-config"
+)
+
+// configDecoder decodes the content of a config file, returning the line numbers
+// of its keys by their dotted path when the format allows it.
+type configDecoder func(content []byte) (data map[string]interface{}, lines map[string]int, err error)
+
+// configDecoders decode config files by their extension, YAML being used for any other extension
+var configDecoders = map[string]configDecoder{
+	".ini": decodeINIConfig,
+	".hcl": decodeHCLConfig,
+}
+
+// decodeConfig decodes a config file according to its extension
+func decodeConfig(content []byte, extension string) (map[string]interface{}, map[string]int, error) {
+	if decoder, ok := configDecoders[strings.ToLower(extension)]; ok {
+		return decoder(content)
+	}
+	return decodeYAMLConfig(content)
+}
+
+// decodeYAMLConfig decodes a YAML config file, an empty file having no values
+func decodeYAMLConfig(content []byte) (map[string]interface{}, map[string]int, error) {
+	data := make(map[string]interface{})
+	if err := yaml.NewDecoder(bytes.NewReader(content)).Decode(&data); err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+
+	lines := make(map[string]int)
+	encrypted := make(map[string]struct{})
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &root); err == nil && len(root.Content) > 0 {
+		collectYAMLKeys(root.Content[0], "", lines, encrypted)
+	}
+	for key, value := range data {
+		data[key] = markEncryptedValues(value, key, encrypted)
+	}
+	return data, lines, nil
+}
+
+// collectYAMLKeys records the line of every dotted key path of a YAML mapping
+// and the keys whose value is tagged as encrypted.
+func collectYAMLKeys(node *yamlv3.Node, prefix string, lines map[string]int, encrypted map[string]struct{}) {
+	if node.Kind != yamlv3.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := prefix + node.Content[i].Value
+		lines[key] = node.Content[i].Line
+		if node.Content[i+1].Tag == encryptedTag {
+			encrypted[key] = struct{}{}
+		}
+		collectYAMLKeys(node.Content[i+1], key+".", lines, encrypted)
+	}
+}
+
+// ConfigValueError is an invalid flag value found in a config file
+type ConfigValueError struct {
+	File string
+	Key  string
+	Line int // 0 when the line is unknown
+	Err  error
+}
+
+func (err *ConfigValueError) Error() string {
+	location := err.File
+	if err.Line > 0 {
+		location += ":" + strconv.Itoa(err.Line)
+	}
+	return fmt.Sprintf("%s: invalid value for %q: %s", location, err.Key, err.Err)
+}
+
+// setConfigValue sets a decoded config value on a flag, checking that
+// its type matches the one of the flag.
+func (flagSet *FlagSet) setConfigValue(value flag.Value, item interface{}) error {
+	if item == nil {
+		return nil
+	}
+	if list, ok := item.([]interface{}); ok {
+		if !isSliceValue(unwrapValue(value)) {
+			return errors.New("expected a single value, got a list")
+		}
+		for _, listItem := range list {
+			listValue, ok := configScalar(listItem)
+			if !ok {
+				return errors.Errorf("unsupported list item of type %T", listItem)
+			}
+			if err := value.Set(flagSet.expandConfigEnv(listValue)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	scalar, ok := configScalar(item)
+	if !ok {
+		return errors.Errorf("unsupported value of type %T", item)
+	}
+	previous := unwrapValue(value).String()
+	if err := value.Set(flagSet.expandConfigEnv(scalar)); err != nil {
+		_ = unwrapValue(value).Set(previous) // invalid values leave the flag unchanged
+		return err
+	}
+	return nil
+}
+
+// configScalar returns the string form of a decoded scalar config value
+func configScalar(item interface{}) (string, bool) {
+	switch item := item.(type) {
+	case string:
+		return item, true
+	case bool:
+		return strconv.FormatBool(item), true
+	case int:
+		return strconv.Itoa(item), true
+	case int64:
+		return strconv.FormatInt(item, 10), true
+	case uint64:
+		return strconv.FormatUint(item, 10), true
+	case float64:
+		return strconv.FormatFloat(item, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// registerBuiltinFlag registers a built-in string flag which can't be set from config
+// files, unless the application already defines a flag with the same name.
+func (flagSet *FlagSet) registerBuiltinFlag(field *string, name, usage string) {
+	if _, ok := flagSet.flagKeys.values[name]; ok || flagSet.CommandLine().Lookup(name) != nil {
+		return
+	}
+	flagSet.StringVar(field, name, "", usage).skipConfig = true
+}
+
+// registerConfigFlags registers the built-in -config and -write-config flags
+func (flagSet *FlagSet) registerConfigFlags() {
+	flagSet.registerBuiltinFlag(&flagSet.explicitConfigFile, configFlagName, "path to the config file to load")
+	flagSet.registerBuiltinFlag(&flagSet.writeConfigFile, writeConfigFlagName, "path to write the resolved configuration to")
+}
+
+// ConfigOnly makes the flag settable only from config files and environment
+// variables, hiding it from the usage and rejecting it on the command line.
+func (flagData *FlagData) ConfigOnly() *FlagData {
+	flagData.configOnly = true
+	return flagData
+}
+
+// checkConfigOnlyFlags returns an error for every config only flag provided on the command line
+func (flagSet *FlagSet) checkConfigOnlyFlags() error {
+	var errs Errors
+	flagSet.CommandLine().Visit(func(fl *flag.Flag) {
+		if data, ok := flagSet.flagKeys.values[fl.Name]; ok && data.configOnly {
+			errs.add(errors.Errorf("flag -%s can only be set in config files or the environment", fl.Name))
+		}
+	})
+	return errs.err()
+}
+
+// SetConfigSearchPaths sets a chain of config files merged by Parse, where earlier
+// paths take precedence over later ones. Paths can reference environment variables
+// like $XDG_CONFIG_HOME, and paths referencing unset variables or missing files are skipped.
+//
+// The chain is merged after the file provided with the -config flag and before the default config file.
+func (flagSet *FlagSet) SetConfigSearchPaths(paths ...string) {
+	flagSet.configSearchPaths = paths
+}
+
+// mergeParseConfigFiles merges the config file provided with the -config flag,
+// then the config search paths, the sections of the config files of the parent
+// command and finally the default config file, so that values of earlier files
+// take precedence over later ones.
+func (flagSet *FlagSet) mergeParseConfigFiles() error {
+	var errs Errors
+	if flagSet.explicitConfigFile != "" {
+		errs.add(flagSet.MergeConfigFile(flagSet.explicitConfigFile))
+	}
+	for _, searchPath := range flagSet.configSearchPaths {
+		configFile, ok := expandSearchPath(searchPath)
+		if !ok {
+			continue
+		}
+		if _, err := os.Stat(configFile); err != nil {
+			continue
+		}
+		errs.add(flagSet.MergeConfigFile(configFile))
+	}
+	errs.add(flagSet.mergeConfigSections())
+	errs.add(flagSet.mergeDefaultConfig())
+	return errs.err()
+}
+
+// MergeConfigFiles merges several config files, values of later files
+// overriding the ones of earlier files. The file which supplied the value
+// of a flag is reported by ConfigFileSource.
+func (flagSet *FlagSet) MergeConfigFiles(files ...string) error {
+	for i := len(files) - 1; i >= 0; i-- {
+		if err := flagSet.MergeConfigFile(files[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConfigFileSource returns the config file or source which supplied the value of a flag
+func (flagSet *FlagSet) ConfigFileSource(name string) (string, bool) {
+	source := flagSet.Source(name)
+	return source.Name, source.Kind == SourceConfigFile
+}
+
+// canonicalName returns the long name of a flag, or its short name if it has no long name
+func (flagSet *FlagSet) canonicalName(name string) string {
+	if data, ok := flagSet.flagKeys.values[name]; ok {
+		return data.name()
+	}
+	return name
+}
+
+// expandSearchPath expands the environment variables of a config search path,
+// returning false if any of them is not set.
+func expandSearchPath(searchPath string) (string, bool) {
+	found := true
+	expanded := os.Expand(searchPath, func(name string) string {
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			found = false
+		}
+		return value
+	})
+	return expanded, found
+}
+
+// WriteConfig writes the resolved values of the flags as a YAML config file,
+// leaving out the password and sensitive flags. An existing file is backed up
+// to a file with the .bak extension when BackupConfig is set.
+func (flagSet *FlagSet) WriteConfig(path string) error {
+	values := yaml.MapSlice{}
+	if flagSet.configVersion > 0 {
+		values = append(values, yaml.MapItem{Key: configVersionKey, Value: flagSet.configVersion})
+	}
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if key != data.name() || data.password || data.sensitive || data.skipConfig {
+			return
+		}
+		if currentFlag := flagSet.CommandLine().Lookup(key); currentFlag != nil {
+			values = append(values, yaml.MapItem{Key: key, Value: configFileValue(unwrapValue(currentFlag.Value))})
+		}
+	})
+
+	content, err := yaml.Marshal(values)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal config file")
+	}
+	return flagSet.saveConfigFile(path, content, flagSet.BackupConfig)
+}
+
+// configFileValue returns the value of a flag as it is written in a config file
+func configFileValue(value flag.Value) interface{} {
+	switch typed := typedValue(value).(type) {
+	case time.Duration:
+		return typed.String()
+	case []byte, flag.Value:
+		return value.String()
+	default:
+		return typed
+	}
+}
@@ -0,0 +1,270 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFlag(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	defaultConfig := filepath.Join(tempDir, "default.yaml")
+	explicitConfig := filepath.Join(tempDir, "explicit.yaml")
+	err = ioutil.WriteFile(defaultConfig, []byte("cli: default\nexplicit: default\ndefault: default"), os.ModePerm)
+	require.Nil(t, err, "could not write default config")
+	err = ioutil.WriteFile(explicitConfig, []byte("cli: explicit\nexplicit: explicit"), os.ModePerm)
+	require.Nil(t, err, "could not write explicit config")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.SetConfigFilePath(defaultConfig)
+
+	var cli, explicit, defaultValue string
+	flagSet.StringVar(&cli, "cli", "", "Value set on the cli")
+	flagSet.StringVar(&explicit, "explicit", "", "Value set in the explicit config")
+	flagSet.StringVar(&defaultValue, "default", "", "Value set in the default config")
+	flagSet.registerConfigFlags()
+
+	err = flag.CommandLine.Parse([]string{"-config", explicitConfig, "-cli", "cli"})
+	require.Nil(t, err, "could not parse flags")
+	err = flagSet.mergeParseConfigFiles()
+	require.Nil(t, err, "could not merge config files")
+
+	require.Equal(t, "cli", cli)
+	require.Equal(t, "explicit", explicit)
+	require.Equal(t, "default", defaultValue)
+	require.NotContains(t, string(flagSet.generateDefaultConfig()), "#config:", "could write config flag to config")
+
+	tearDown(t.Name())
+}
+
+func TestConfigFlagNotOverridden(t *testing.T) {
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+
+	var config string
+	flagSet.StringVarP(&config, "config", "c", "", "Application config")
+	require.NotPanics(t, flagSet.registerConfigFlags, "could not keep application config flag")
+	require.Empty(t, flagSet.explicitConfigFile)
+
+	tearDown(t.Name())
+}
+
+func TestConfigSearchPaths(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	os.Setenv("GOFLAGS_TEST_CONFIG_HOME", tempDir)
+	defer os.Unsetenv("GOFLAGS_TEST_CONFIG_HOME")
+
+	localConfig := filepath.Join(tempDir, "local.yaml")
+	systemConfig := filepath.Join(tempDir, "system.yaml")
+	err = ioutil.WriteFile(localConfig, []byte("local: local\nsystem: local"), os.ModePerm)
+	require.Nil(t, err, "could not write local config")
+	err = ioutil.WriteFile(systemConfig, []byte("local: system\nsystem: system\nonly-system: system"), os.ModePerm)
+	require.Nil(t, err, "could not write system config")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.DisableAutoConfig()
+	flagSet.SetConfigSearchPaths(
+		"$GOFLAGS_TEST_CONFIG_HOME/local.yaml",
+		"$GOFLAGS_TEST_UNSET_VARIABLE/system.yaml",
+		filepath.Join(tempDir, "missing.yaml"),
+		systemConfig,
+	)
+
+	var local, system, onlySystem string
+	flagSet.StringVar(&local, "local", "", "Local value")
+	flagSet.StringVar(&system, "system", "", "System value")
+	flagSet.StringVar(&onlySystem, "only-system", "", "System only value")
+
+	err = flagSet.mergeParseConfigFiles()
+	require.Nil(t, err, "could not merge config files")
+	require.Equal(t, "local", local)
+	require.Equal(t, "local", system)
+	require.Equal(t, "system", onlySystem)
+
+	tearDown(t.Name())
+}
+
+func TestConfigValueValidation(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte(`threads: ten
+verbose:
+  - true
+format: xml
+rate-limit: 1.5
+targets:
+  - a.com
+  - 10
+output:
+  file: out.txt
+`), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var threads, rateLimit int
+	var verbose bool
+	var format, output string
+	var targets StringSlice
+	flagSet.IntVar(&threads, "threads", 1, "Threads")
+	flagSet.IntVar(&rateLimit, "rate-limit", 1, "Rate limit")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flagSet.EnumVar(&format, "format", "json", []string{"json", "yaml"}, "Output format")
+	flagSet.StringVar(&output, "output", "", "Output file")
+	flagSet.StringSliceVar(&targets, "targets", nil, "Targets")
+
+	err = flagSet.MergeConfigFile(configFile)
+	require.NotNil(t, err, "could merge invalid config")
+
+	errs, ok := err.(Errors)
+	require.True(t, ok, "could not get aggregated errors")
+	lines := make(map[string]int)
+	for _, err := range errs {
+		valueErr, ok := err.(*ConfigValueError)
+		require.True(t, ok, "could not get config value error")
+		require.Equal(t, configFile, valueErr.File)
+		lines[valueErr.Key] = valueErr.Line
+	}
+	require.Equal(t, map[string]int{"threads": 1, "verbose": 2, "format": 4, "rate-limit": 5, "output": 9}, lines)
+	require.Contains(t, err.Error(), configFile+":1: invalid value for \"threads\"")
+	require.Equal(t, StringSlice{"a.com", "10"}, targets, "could not set valid values")
+
+	tearDown(t.Name())
+}
+
+func TestWriteConfig(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("threads: 10\ntargets:\n  - a.com\n  - b.com"), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+	writtenFile := filepath.Join(tempDir, "written.yaml")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.SetConfigFilePath("")
+	var threads int
+	var verbose bool
+	var token string
+	var targets StringSlice
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flagSet.StringVar(&token, "token", "", "API token").Sensitive()
+	flagSet.StringSliceVar(&targets, "targets", nil, "Targets")
+
+	os.Args = []string{os.Args[0], "-config", configFile, "-verbose", "-token", "secret", "-write-config", writtenFile}
+	err = flagSet.Parse()
+	require.Nil(t, err, "could not parse flags")
+
+	content, err := ioutil.ReadFile(writtenFile)
+	require.Nil(t, err, "could not read written config")
+	require.Equal(t, "threads: 10\nverbose: true\ntargets:\n- a.com\n- b.com\n", string(content))
+
+	tearDown(t.Name())
+	flagSet = NewFlagSet()
+	flagSet.SetConfigFilePath("")
+	threads, verbose, targets = 0, false, nil
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flagSet.StringSliceVar(&targets, "targets", nil, "Targets")
+	err = flagSet.MergeConfigFile(writtenFile)
+	require.Nil(t, err, "could not merge written config")
+	require.Equal(t, 10, threads)
+	require.True(t, verbose)
+	require.Equal(t, StringSlice{"a.com", "b.com"}, targets)
+
+	tearDown(t.Name())
+}
+
+func TestMergeConfigFiles(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	baseConfig := filepath.Join(tempDir, "base.yaml")
+	overrideConfig := filepath.Join(tempDir, "override.yaml")
+	err = ioutil.WriteFile(baseConfig, []byte("threads: 5\nverbose: true"), os.ModePerm)
+	require.Nil(t, err, "could not write base config")
+	err = ioutil.WriteFile(overrideConfig, []byte("threads: 10"), os.ModePerm)
+	require.Nil(t, err, "could not write override config")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var threads int
+	var verbose bool
+	var output string
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flagSet.StringVar(&output, "output", "", "Output file")
+
+	err = flagSet.MergeConfigFiles(baseConfig, overrideConfig)
+	require.Nil(t, err, "could not merge config files")
+	require.Equal(t, 10, threads)
+	require.True(t, verbose)
+
+	source, ok := flagSet.ConfigFileSource("t")
+	require.True(t, ok, "could not get config file of flag")
+	require.Equal(t, overrideConfig, source)
+	source, _ = flagSet.ConfigFileSource("verbose")
+	require.Equal(t, baseConfig, source)
+	_, ok = flagSet.ConfigFileSource("output")
+	require.False(t, ok, "could get config file of unset flag")
+
+	tearDown(t.Name())
+}
+
+func TestConfigOnly(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("batch-size: 50"), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.SetConfigFilePath(configFile)
+	var batchSize, threads int
+	flagSet.IntVarP(&batchSize, "batch-size", "b", 100, "Internal batch size").ConfigOnly()
+	flagSet.IntVar(&threads, "threads", 10, "Threads")
+
+	os.Args = []string{os.Args[0]}
+	err = flagSet.Parse()
+	require.Nil(t, err, "could not parse flags")
+	require.Equal(t, 50, batchSize, "could not set config only flag from config")
+	require.Contains(t, string(flagSet.generateDefaultConfig()), "#batch-size: 100")
+
+	usage := &bytes.Buffer{}
+	flag.CommandLine.SetOutput(usage)
+	flagSet.usageFunc()
+	require.NotContains(t, usage.String(), "batch-size", "could show config only flag in usage")
+
+	tearDown(t.Name())
+	flagSet = NewFlagSet()
+	flagSet.SetConfigFilePath("")
+	flagSet.IntVarP(&batchSize, "batch-size", "b", 100, "Internal batch size").ConfigOnly()
+	os.Args = []string{os.Args[0], "-b", "10"}
+	err = flagSet.Parse()
+	require.NotNil(t, err, "could set config only flag on the command line")
+
+	tearDown(t.Name())
+}
@@ -0,0 +1,129 @@
+package goflags
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MergeConfigDir merges a directory holding one file per flag, named after the flag
+// and containing its value, like the ConfigMaps and Secrets mounted by Kubernetes.
+// Hidden files, like the ..data link of Kubernetes volumes, are ignored.
+func (flagSet *FlagSet) MergeConfigDir(dir string) error {
+	data, err := readConfigDir(dir)
+	if err != nil {
+		return err
+	}
+	return flagSet.mergeConfigData(data, dir, nil)
+}
+
+// WatchConfigDir polls a directory merged with MergeConfigDir for changes at the
+// interval, updating the flags which were not set by other sources. The callback is
+// called after each reload with its error, from the watching goroutine.
+//
+// The flags are updated under a lock taken by the Get methods and Source, so the
+// values must be read with them while watching instead of from the variables the
+// flags were registered with.
+//
+// The returned function stops watching the directory.
+func (flagSet *FlagSet) WatchConfigDir(dir string, interval time.Duration, onReload func(err error)) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		snapshot := configDirSnapshot(dir)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			current := configDirSnapshot(dir)
+			if current == snapshot {
+				continue
+			}
+			snapshot = current
+			err := flagSet.reloadConfigDir(dir)
+			if onReload != nil {
+				onReload(err)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// reloadConfigDir sets the flags having their default value or a value
+// from the directory to the current values of the directory.
+func (flagSet *FlagSet) reloadConfigDir(dir string) error {
+	data, err := readConfigDir(dir)
+	if err != nil {
+		return err
+	}
+	flagSet.valuesMutex.Lock()
+	defer flagSet.valuesMutex.Unlock()
+
+	var errs Errors
+	flagSet.CommandLine().VisitAll(func(fl *flag.Flag) {
+		item, ok := data[fl.Name]
+		if flagData, known := flagSet.flagKeys.values[fl.Name]; !ok || (known && flagData.skipConfig) {
+			return
+		}
+		if source := flagSet.flagSources[flagSet.canonicalName(fl.Name)]; source.Kind != SourceDefault && source != (Source{Kind: SourceConfigFile, Name: dir}) {
+			return
+		}
+		if err := replaceValue(unwrapValue(fl.Value), item.(string)); err != nil {
+			errs = append(errs, &ConfigValueError{File: dir, Key: fl.Name, Err: err})
+			return
+		}
+		flagSet.setSource(fl.Name, Source{Kind: SourceConfigFile, Name: dir})
+	})
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// readConfigDir reads the values of the files of a config directory by file name
+func readConfigDir(dir string) (map[string]interface{}, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read config directory")
+	}
+	data := make(map[string]interface{})
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue // directory or dangling link
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read config file %s", path)
+		}
+		data[file.Name()] = strings.TrimRight(string(content), "\r\n")
+	}
+	return data, nil
+}
+
+// configDirSnapshot returns a summary of the files of a directory changing with their content
+func configDirSnapshot(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	sort.Strings(files)
+	var snapshot strings.Builder
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			fmt.Fprintf(&snapshot, "%s:%d:%d\n", file, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return snapshot.String()
+}
@@ -0,0 +1,101 @@
+package goflags
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeConfigDir(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	require.Nil(t, ioutil.WriteFile(filepath.Join(tempDir, "threads"), []byte("10\n"), os.ModePerm))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(tempDir, "targets"), []byte("a.com,b.com"), os.ModePerm))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(tempDir, "token"), []byte("secret"), os.ModePerm))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(tempDir, ".hidden"), []byte("ignored"), os.ModePerm))
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var threads int
+	var targets StringSlice
+	var token string
+	flagSet.IntVar(&threads, "threads", 1, "Threads")
+	flagSet.StringSliceVar(&targets, "targets", nil, "Targets")
+	flagSet.StringVar(&token, "token", "", "API token")
+
+	err = flagSet.MergeConfigDir(tempDir)
+	require.Nil(t, err, "could not merge config directory")
+	require.Equal(t, 10, threads)
+	require.Equal(t, StringSlice{"a.com", "b.com"}, targets)
+	require.Equal(t, "secret", token)
+	require.Equal(t, Source{Kind: SourceConfigFile, Name: tempDir}, flagSet.Source("threads"))
+
+	reloaded := make(chan error, 1)
+	stop := flagSet.WatchConfigDir(tempDir, 10*time.Millisecond, func(err error) { reloaded <- err })
+	defer stop()
+
+	time.Sleep(20 * time.Millisecond)
+	require.Nil(t, ioutil.WriteFile(filepath.Join(tempDir, "targets"), []byte("c.com,d.com,e.com"), os.ModePerm))
+	select {
+	case err := <-reloaded:
+		require.Nil(t, err, "could not reload config directory")
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "could not watch config directory")
+	}
+	require.Equal(t, StringSlice{"c.com", "d.com", "e.com"}, targets, "could not replace reloaded slice")
+
+	tearDown(t.Name())
+}
+
+func TestWatchConfigDirConcurrentReads(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	require.Nil(t, ioutil.WriteFile(filepath.Join(tempDir, "threads"), []byte("1"), os.ModePerm))
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var threads int
+	flagSet.IntVar(&threads, "threads", 0, "Threads")
+	require.Nil(t, flagSet.MergeConfigDir(tempDir), "could not merge config directory")
+
+	reloaded := make(chan error, 10)
+	stop := flagSet.WatchConfigDir(tempDir, time.Millisecond, func(err error) { reloaded <- err })
+	defer stop()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 2; i <= 5; i++ {
+			time.Sleep(5 * time.Millisecond)
+			_ = ioutil.WriteFile(filepath.Join(tempDir, "threads"), []byte(strconv.Itoa(i)), os.ModePerm)
+			select {
+			case <-reloaded:
+			case <-time.After(2 * time.Second):
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			value, err := flagSet.GetInt("threads")
+			require.Nil(t, err, "could not get reloaded value")
+			require.Equal(t, 5, value)
+			return
+		default:
+		}
+		_, err := flagSet.GetInt("threads")
+		require.Nil(t, err, "could not get value while reloading")
+		_ = flagSet.Source("threads")
+	}
+}
@@ -0,0 +1,193 @@
+package goflags
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// Required makes Parse fail when the flag is not provided by any source
+func (flagData *FlagData) Required() *FlagData {
+	flagData.required = true
+	return flagData
+}
+
+// SeeAlso sets the flags mentioned by the errors of the constraints and
+// validators of the flag, like "see also -rate-limit-minute", to guide
+// users toward the correct combination of flags.
+func (flagData *FlagData) SeeAlso(names ...string) *FlagData {
+	flagData.related = append(flagData.related, names...)
+	return flagData
+}
+
+// MarkRequired makes Parse fail when any of the flags is not provided by any source
+func (flagSet *FlagSet) MarkRequired(names ...string) {
+	for _, name := range names {
+		flagSet.constrainedFlag(name).required = true
+	}
+}
+
+// MarkRequiredTogether makes Parse fail when some but not all of the flags are provided
+func (flagSet *FlagSet) MarkRequiredTogether(names ...string) {
+	for _, name := range names {
+		flagSet.constrainedFlag(name)
+	}
+	flagSet.requiredTogether = append(flagSet.requiredTogether, names)
+}
+
+// MarkOneRequired makes Parse fail when none of the alternative flags is provided,
+// with a hint listing the usage of each of them.
+func (flagSet *FlagSet) MarkOneRequired(names ...string) {
+	for _, name := range names {
+		flagSet.constrainedFlag(name)
+	}
+	flagSet.oneRequired = append(flagSet.oneRequired, names)
+}
+
+// conditionalRequirement is a flag required when its condition holds after all sources are merged
+type conditionalRequirement struct {
+	name      string
+	condition func(flagSet *FlagSet) bool
+	reason    string
+}
+
+// RequireIf makes Parse fail when the flag is not provided while the condition,
+// evaluated once all sources are merged, holds.
+func (flagSet *FlagSet) RequireIf(name string, condition func(flagSet *FlagSet) bool) {
+	flagSet.constrainedFlag(name)
+	flagSet.requirements = append(flagSet.requirements, conditionalRequirement{name: name, condition: condition})
+}
+
+// RequiredWhen makes Parse fail when the flag is not provided while the other flag is
+func (flagSet *FlagSet) RequiredWhen(name, other string) {
+	flagSet.constrainedFlag(name)
+	flagSet.constrainedFlag(other)
+	flagSet.requirements = append(flagSet.requirements, conditionalRequirement{
+		name:      name,
+		condition: func(flagSet *FlagSet) bool { return flagSet.Changed(other) },
+		reason:    "when -" + other + " is provided",
+	})
+}
+
+// constrainedFlag returns the data of a flag referenced by a constraint, panicking if
+// it is unknown unless the FlagSet is in library mode.
+func (flagSet *FlagSet) constrainedFlag(name string) *FlagData {
+	data, ok := flagSet.flagKeys.values[name]
+	if !ok {
+		if !flagSet.LibraryMode {
+			panic("goflags: unknown flag -" + name + " in constraint")
+		}
+		flagSet.registrationErrs.add(errors.Errorf("unknown flag -%s in constraint", name))
+		return &FlagData{}
+	}
+	return data
+}
+
+// checkRequired returns a single error listing all required flags which were not provided
+func (flagSet *FlagSet) checkRequired() error {
+	var missing []string
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if data.required && key == data.name() && !flagSet.Changed(key) {
+			missing = append(missing, key)
+		}
+	})
+	if len(missing) > 0 {
+		return errors.Errorf("missing required flags: %s%s", flagList(missing), flagSet.seeAlso(missing...))
+	}
+	return nil
+}
+
+// checkRequiredTogether returns an error for every group of flags required together
+// which is partially provided, naming its missing flags.
+func (flagSet *FlagSet) checkRequiredTogether() error {
+	var errs Errors
+	for _, group := range flagSet.requiredTogether {
+		var missing []string
+		for _, name := range group {
+			if !flagSet.Changed(name) {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 && len(missing) < len(group) {
+			errs.add(errors.Errorf("flags %s must be provided together: missing %s%s", flagList(group), flagList(missing), flagSet.seeAlso(group...)))
+		}
+	}
+	return errs.err()
+}
+
+// flagList returns the names of the flags prefixed with a dash and separated with commas
+func flagList(names []string) string {
+	prefixed := make([]string, len(names))
+	for i, name := range names {
+		prefixed[i] = "-" + name
+	}
+	return strings.Join(prefixed, ", ")
+}
+
+// checkOneRequired returns an error for every group of alternative flags none of which is provided
+func (flagSet *FlagSet) checkOneRequired() error {
+	var errs Errors
+	for _, group := range flagSet.oneRequired {
+		var provided bool
+		for _, name := range group {
+			provided = provided || flagSet.Changed(name)
+		}
+		if provided {
+			continue
+		}
+
+		hint := &bytes.Buffer{}
+		writer := tabwriter.NewWriter(hint, 0, 0, 2, ' ', 0)
+		for _, name := range group {
+			fmt.Fprintf(writer, "\n  -%s\t%s", name, flagSet.flagKeys.values[name].usage)
+		}
+		writer.Flush()
+		errs.add(errors.Errorf("one of %s is required%s:%s", flagList(group), flagSet.seeAlso(group...), hint.String()))
+	}
+	return errs.err()
+}
+
+// checkConditionalRequirements returns an error for every flag required by a condition which is not provided
+func (flagSet *FlagSet) checkConditionalRequirements() error {
+	var errs Errors
+	for _, requirement := range flagSet.requirements {
+		if flagSet.Changed(requirement.name) || !requirement.condition(flagSet) {
+			continue
+		}
+		if requirement.reason != "" {
+			errs.add(errors.Errorf("-%s is required %s%s", requirement.name, requirement.reason, flagSet.seeAlso(requirement.name)))
+		} else {
+			errs.add(errors.Errorf("-%s is required%s", requirement.name, flagSet.seeAlso(requirement.name)))
+		}
+	}
+	return errs.err()
+}
+
+// seeAlso returns the hint naming the flags related to the flags of an error,
+// or an empty string if they have none.
+func (flagSet *FlagSet) seeAlso(names ...string) string {
+	var related []string
+	for _, name := range names {
+		data, ok := flagSet.flagKeys.values[name]
+		if !ok {
+			continue
+		}
+		for _, other := range data.related {
+			if !sliceContains(names, other) && !sliceContains(related, other) {
+				related = append(related, other)
+			}
+		}
+	}
+	return seeAlsoHint(related)
+}
+
+// seeAlsoHint returns the hint naming the related flags, or an empty string if there are none
+func seeAlsoHint(related []string) string {
+	if len(related) == 0 {
+		return ""
+	}
+	return " (see also " + flagList(related) + ")"
+}
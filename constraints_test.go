@@ -0,0 +1,148 @@
+package goflags
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newConstraintsFlagSet returns a flag set not exiting on parse errors and without config file
+func newConstraintsFlagSet(name string) *FlagSet {
+	tearDown(name)
+	flag.CommandLine.Init(name, flag.ContinueOnError)
+	flag.CommandLine.SetOutput(ioutil.Discard)
+	flagSet := NewFlagSet()
+	flagSet.SetConfigFilePath("")
+	return flagSet
+}
+
+func TestRequired(t *testing.T) {
+	flagSet := newConstraintsFlagSet(t.Name())
+	var target, output, proxy string
+	flagSet.StringVarP(&target, "target", "u", "", "Target").Required()
+	flagSet.StringVar(&output, "output", "", "Output file")
+	flagSet.StringVar(&proxy, "proxy", "", "Proxy").Env("GOFLAGS_TEST_REQUIRED_PROXY")
+	flagSet.MarkRequired("output", "proxy")
+
+	os.Args = []string{os.Args[0]}
+	err := flagSet.Parse()
+	require.NotNil(t, err, "could parse without required flags")
+	require.Equal(t, "missing required flags: -target, -output, -proxy", err.Error())
+
+	flagSet = newConstraintsFlagSet(t.Name())
+	flagSet.StringVarP(&target, "target", "u", "", "Target").Required()
+	flagSet.StringVar(&proxy, "proxy", "", "Proxy").Env("GOFLAGS_TEST_REQUIRED_PROXY").Required()
+	os.Setenv("GOFLAGS_TEST_REQUIRED_PROXY", "http://proxy:8080")
+	defer os.Unsetenv("GOFLAGS_TEST_REQUIRED_PROXY")
+
+	os.Args = []string{os.Args[0], "-u", "example.com"}
+	err = flagSet.Parse()
+	require.Nil(t, err, "could not parse with required flags from cli and env")
+
+	require.Panics(t, func() { flagSet.MarkRequired("unknown") })
+	tearDown(t.Name())
+}
+
+func TestMarkRequiredTogether(t *testing.T) {
+	for _, test := range []struct {
+		args []string
+		err  string
+	}{
+		{args: nil},
+		{args: []string{"-client-cert", "cert.pem", "-client-key", "key.pem"}},
+		{args: []string{"-client-cert", "cert.pem"}, err: "flags -client-cert, -client-key must be provided together: missing -client-key"},
+		{args: []string{"-client-key", "key.pem"}, err: "flags -client-cert, -client-key must be provided together: missing -client-cert"},
+	} {
+		flagSet := newConstraintsFlagSet(t.Name())
+		var clientCert, clientKey string
+		flagSet.StringVar(&clientCert, "client-cert", "", "Client certificate")
+		flagSet.StringVar(&clientKey, "client-key", "", "Client key")
+		flagSet.MarkRequiredTogether("client-cert", "client-key")
+
+		os.Args = append([]string{os.Args[0]}, test.args...)
+		err := flagSet.Parse()
+		if test.err == "" {
+			require.Nil(t, err, "could not parse %v", test.args)
+		} else {
+			require.NotNil(t, err, "could parse %v", test.args)
+			require.Equal(t, test.err, err.Error())
+		}
+	}
+	tearDown(t.Name())
+}
+
+func TestMarkOneRequired(t *testing.T) {
+	for _, args := range [][]string{nil, {"-list", "targets.txt"}} {
+		flagSet := newConstraintsFlagSet(t.Name())
+		var target, list string
+		var stdin bool
+		flagSet.StringVarP(&target, "target", "u", "", "Target to scan")
+		flagSet.StringVarP(&list, "list", "l", "", "File of targets to scan")
+		flagSet.BoolVar(&stdin, "stdin", false, "Read targets from stdin")
+		flagSet.MarkOneRequired("target", "list", "stdin")
+
+		os.Args = append([]string{os.Args[0]}, args...)
+		err := flagSet.Parse()
+		if args != nil {
+			require.Nil(t, err, "could not parse with one of the flags")
+			continue
+		}
+		require.NotNil(t, err, "could parse without any of the flags")
+		require.Equal(t, "one of -target, -list, -stdin is required:\n  -target  Target to scan\n  -list    File of targets to scan\n  -stdin   Read targets from stdin", err.Error())
+	}
+	tearDown(t.Name())
+}
+
+func TestConditionalRequirements(t *testing.T) {
+	for _, test := range []struct {
+		args []string
+		err  string
+	}{
+		{args: nil},
+		{args: []string{"-tls-cert", "cert.pem"}, err: "-tls-key is required when -tls-cert is provided"},
+		{args: []string{"-tls-cert", "cert.pem", "-tls-key", "key.pem"}},
+		{args: []string{"-format", "csv"}, err: "-output-file is required"},
+		{args: []string{"-format", "csv", "-output-file", "out.csv"}},
+	} {
+		flagSet := newConstraintsFlagSet(t.Name())
+		var tlsCert, tlsKey, format, outputFile string
+		flagSet.StringVar(&tlsCert, "tls-cert", "", "TLS certificate")
+		flagSet.StringVar(&tlsKey, "tls-key", "", "TLS key")
+		flagSet.StringVar(&format, "format", "", "Output format")
+		flagSet.StringVar(&outputFile, "output-file", "", "Output file")
+		flagSet.RequiredWhen("tls-key", "tls-cert")
+		flagSet.RequireIf("output-file", func(flagSet *FlagSet) bool { return flagSet.Source("format").Kind == SourceCLI })
+
+		os.Args = append([]string{os.Args[0]}, test.args...)
+		err := flagSet.Parse()
+		if test.err == "" {
+			require.Nil(t, err, "could not parse %v", test.args)
+		} else {
+			require.NotNil(t, err, "could parse %v", test.args)
+			require.Equal(t, test.err, err.Error())
+		}
+	}
+	tearDown(t.Name())
+}
+
+func TestSeeAlso(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.CommandLine().SetOutput(ioutil.Discard)
+	var rateLimit, rateLimitMinute int
+	var username, password string
+	flagSet.IntVar(&rateLimit, "rate-limit", 150, "Requests per second").WithMax(1000).SeeAlso("rate-limit-minute")
+	flagSet.IntVar(&rateLimitMinute, "rate-limit-minute", 0, "Requests per minute")
+	flagSet.StringVar(&username, "username", "", "Username").SeeAlso("password", "rate-limit")
+	flagSet.StringVar(&password, "password", "", "Password").SeeAlso("username")
+	flagSet.MarkRequiredTogether("username", "password")
+
+	err := flagSet.ParseArgs([]string{"-rate-limit", "5000"})
+	require.EqualError(t, err, "invalid value for -rate-limit: must be at most 1000 (see also -rate-limit-minute)")
+
+	err = flagSet.ParseArgs([]string{"-rate-limit", "10", "-username", "admin"})
+	require.EqualError(t, err, "flags -username, -password must be provided together: missing -password (see also -rate-limit)")
+}
@@ -0,0 +1,69 @@
+package goflags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MarkDeprecated marks a flag as deprecated, showing the message next to it in
+// the usage and in a warning printed once when the flag is provided. The flag keeps
+// working, and when a replacement flag is given its value is forwarded to that flag
+// unless it is provided too. An empty replacement forwards the value nowhere:
+//
+//	flagSet.MarkDeprecated("out", "use -output instead", "output")
+//
+// NOTE: MarkDeprecated panics if the flag or its replacement is not defined, unless
+// the FlagSet is in library mode.
+func (flagSet *FlagSet) MarkDeprecated(name, message, replacement string) {
+	data, ok := flagSet.flagKeys.values[name]
+	if !ok {
+		flagSet.fail(errors.Errorf("flag %s is not defined", name))
+		return
+	}
+	if replacement != "" {
+		replacementData, ok := flagSet.flagKeys.values[replacement]
+		if !ok || replacementData == data {
+			flagSet.fail(errors.Errorf("replacement flag %s of %s is not defined", replacement, name))
+			return
+		}
+		data.replacedBy = replacementData.name()
+	}
+	data.deprecated = message
+}
+
+// handleDeprecated warns about the deprecated flags which were provided,
+// forwarding their values to the flags replacing them.
+func (flagSet *FlagSet) handleDeprecated() error {
+	var errs Errors
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if data.deprecated == "" || key != data.name() || !flagSet.Changed(key) {
+			return
+		}
+		if !data.warned {
+			fmt.Fprintf(flagSet.CommandLine().Output(), "Flag -%s is deprecated, %s\n", key, data.deprecated)
+			data.warned = true
+		}
+
+		replacement := data.replacedBy
+		if replacement == "" || flagSet.Changed(replacement) {
+			return
+		}
+		value := unwrapValue(flagSet.CommandLine().Lookup(key).Value)
+		input := value.String()
+		if slice, ok := value.(*StringSlice); ok {
+			items := make([]string, 0, len(*slice))
+			for _, item := range *slice {
+				items = append(items, quoteSliceItem(item))
+			}
+			input = strings.Join(items, ",")
+		}
+		if err := replaceValue(unwrapValue(flagSet.CommandLine().Lookup(replacement).Value), input); err != nil {
+			errs.add(&FlagValueError{Flag: replacement, Err: errors.Wrapf(err, "could not forward value of deprecated flag -%s", key)})
+			return
+		}
+		flagSet.setSource(replacement, flagSet.Source(key))
+	})
+	return errs.err()
+}
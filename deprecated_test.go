@@ -0,0 +1,72 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkDeprecated(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var output, oldOutput string
+	flagSet.StringVar(&output, "output", "", "Output file").Required()
+	flagSet.StringVar(&oldOutput, "out", "", "Output file")
+	flagSet.MarkDeprecated("out", "use -output instead", "output")
+
+	messages := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(messages)
+	err := flagSet.ParseArgs([]string{"-out", "result.txt"})
+	require.Nil(t, err, "could not parse deprecated flag")
+	require.Equal(t, "result.txt", oldOutput)
+	require.Equal(t, "result.txt", output, "value was not forwarded to the replacement")
+	require.Equal(t, SourceCLI, flagSet.Source("output").Kind)
+
+	err = flagSet.ParseArgs([]string{"-out", "other.txt", "-output", "new.txt"})
+	require.Nil(t, err, "could not parse deprecated flag")
+	require.Equal(t, "new.txt", output, "forwarded value replaced the provided one")
+	require.Equal(t, 1, strings.Count(messages.String(), "Flag -out is deprecated, use -output instead\n"), "warning was not printed once")
+
+	messages.Reset()
+	flagSet.usageFunc()
+	require.Contains(t, messages.String(), "Output file (deprecated: use -output instead)\n")
+
+	require.PanicsWithValue(t, "flag missing is not defined", func() {
+		flagSet.MarkDeprecated("missing", "use -output instead", "")
+	})
+	require.PanicsWithValue(t, "replacement flag missing of out is not defined", func() {
+		flagSet.MarkDeprecated("out", "use -output instead", "missing")
+	})
+}
+
+func TestMarkDeprecatedWithoutReplacement(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var output, oldOutput string
+	flagSet.StringVar(&output, "output", "", "Output file")
+	flagSet.StringVar(&oldOutput, "out", "", "Output file")
+	flagSet.MarkDeprecated("out", "use -output instead", "")
+	flagSet.CommandLine().SetOutput(&bytes.Buffer{})
+
+	err := flagSet.ParseArgs([]string{"-out", "result.txt"})
+	require.Nil(t, err, "could not parse deprecated flag")
+	require.Equal(t, "result.txt", oldOutput)
+	require.Empty(t, output, "value was forwarded to a flag named by the message")
+}
+
+func TestMarkDeprecatedSlice(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var targets, oldTargets StringSlice
+	flagSet.StringSliceVar(&targets, "target", nil, "Targets")
+	flagSet.StringSliceVar(&oldTargets, "host", nil, "Targets")
+	flagSet.MarkDeprecated("host", "use -target instead", "target")
+	flagSet.CommandLine().SetOutput(&bytes.Buffer{})
+
+	err := flagSet.ParseArgs([]string{"-host", "a.com,b.com", "-host", "c d,e"})
+	require.Nil(t, err, "could not forward deprecated slice flag")
+	require.Equal(t, StringSlice{"a.com", "b.com", "c d", "e"}, targets)
+}
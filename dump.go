@@ -0,0 +1,68 @@
+package goflags
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// DumpFormat is the output format of DumpResolved
+type DumpFormat string
+
+const (
+	// DumpText dumps the flags as an aligned table
+	DumpText DumpFormat = "text"
+	// DumpJSON dumps the flags as a JSON array
+	DumpJSON DumpFormat = "json"
+	// DumpYAML dumps the flags as a YAML list
+	DumpYAML DumpFormat = "yaml"
+)
+
+// resolvedFlag is the resolved value of a flag with its source
+type resolvedFlag struct {
+	Name   string      `json:"name" yaml:"name"`
+	Value  interface{} `json:"value" yaml:"value"`
+	Source string      `json:"source" yaml:"source"`
+}
+
+// DumpResolved writes the value of every flag after Parse along with its source,
+// redacting the values of sensitive and password flags.
+func (flagSet *FlagSet) DumpResolved(w io.Writer, format DumpFormat) error {
+	var resolved []resolvedFlag
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		currentFlag := flagSet.CommandLine().Lookup(key)
+		if key != data.name() || currentFlag == nil {
+			return
+		}
+		value := configFileValue(unwrapValue(currentFlag.Value))
+		if (data.sensitive || data.password) && currentFlag.Value.String() != "" {
+			value = redactedValue
+		}
+		resolved = append(resolved, resolvedFlag{Name: key, Value: value, Source: flagSet.Source(key).String()})
+	})
+
+	switch format {
+	case DumpText:
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, item := range resolved {
+			fmt.Fprintf(writer, "%s\t%v\t%s\n", item.Name, item.Value, item.Source)
+		}
+		return writer.Flush()
+	case DumpJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(resolved)
+	case DumpYAML:
+		content, err := yaml.Marshal(resolved)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal resolved flags")
+		}
+		_, err = w.Write(content)
+		return err
+	}
+	return errors.Errorf("unsupported dump format %q", format)
+}
@@ -0,0 +1,49 @@
+package goflags
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpResolved(t *testing.T) {
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.SetConfigFilePath("")
+	var threads int
+	var token string
+	var targets StringSlice
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads")
+	flagSet.StringVar(&token, "token", "", "API token").Sensitive()
+	flagSet.StringSliceVar(&targets, "targets", nil, "Targets")
+
+	os.Args = []string{os.Args[0], "-t", "5", "-token", "secret", "-targets", "a.com,b.com"}
+	err := flagSet.Parse()
+	require.Nil(t, err, "could not parse flags")
+
+	output := &bytes.Buffer{}
+	err = flagSet.DumpResolved(output, DumpText)
+	require.Nil(t, err, "could not dump flags")
+	require.Contains(t, output.String(), "threads       5              CLI\n")
+	require.Contains(t, output.String(), "token         [REDACTED]     CLI\n")
+	require.NotContains(t, output.String(), "secret")
+
+	output.Reset()
+	err = flagSet.DumpResolved(output, DumpJSON)
+	require.Nil(t, err, "could not dump flags")
+	var resolved []resolvedFlag
+	require.Nil(t, json.Unmarshal(output.Bytes(), &resolved))
+	require.Equal(t, resolvedFlag{Name: "targets", Value: []interface{}{"a.com", "b.com"}, Source: "CLI"}, resolved[2])
+
+	output.Reset()
+	err = flagSet.DumpResolved(output, DumpYAML)
+	require.Nil(t, err, "could not dump flags")
+	require.Contains(t, output.String(), "- name: threads\n  value: 5\n  source: CLI\n")
+
+	require.NotNil(t, flagSet.DumpResolved(output, "xml"), "could dump unsupported format")
+
+	tearDown(t.Name())
+}
@@ -0,0 +1,165 @@
+package goflags
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/pkg/errors"
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// configKeyEnv is the environment variable holding the age identities decrypting config files
+	configKeyEnv = "GOFLAGS_AGE_KEY"
+	// configKeyringKey is the keyring key of the age identities decrypting config files
+	configKeyringKey = "goflags-age-key"
+	// encryptedTag is the YAML tag of encrypted config values
+	encryptedTag = "!encrypted"
+	// ageHeader starts binary age encrypted files
+	ageHeader = "age-encryption.org/v1"
+)
+
+// encryptedValue is a config value holding base64 or armored age ciphertext
+type encryptedValue string
+
+// SetConfigKey sets the age identities, one AGE-SECRET-KEY-1... per line, decrypting
+// encrypted config files and values. They are read from the GOFLAGS_AGE_KEY
+// environment variable by default, then from the OS keyring when it is used,
+// where they are stored with StoreConfigKey.
+//
+// Whole config files can be encrypted with age, or with sops for age recipients,
+// while single YAML values are encrypted with EncryptConfigValue and tagged as !encrypted:
+//
+//	token: !encrypted YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBy...
+func (flagSet *FlagSet) SetConfigKey(key string) {
+	flagSet.configKey = key
+}
+
+// StoreConfigKey stores the age identities decrypting config files in the OS keyring,
+// to be read when the keyring is used and no key is set otherwise.
+func (flagSet *FlagSet) StoreConfigKey(key string) error {
+	if err := keyring.Set(flagSet.appName(), configKeyringKey, key); err != nil {
+		return errors.Wrap(err, "could not store config key in keyring")
+	}
+	return nil
+}
+
+// EncryptConfigValue encrypts a config value for the age recipients (age1...),
+// returning the base64 ciphertext to write after the !encrypted YAML tag.
+func EncryptConfigValue(value string, recipients ...string) (string, error) {
+	parsed, err := age.ParseRecipients(strings.NewReader(strings.Join(recipients, "\n")))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid recipients")
+	}
+	ciphertext := &bytes.Buffer{}
+	writer, err := age.Encrypt(ciphertext, parsed...)
+	if err != nil {
+		return "", errors.Wrap(err, "could not encrypt value")
+	}
+	if _, err := io.WriteString(writer, value); err != nil {
+		return "", errors.Wrap(err, "could not encrypt value")
+	}
+	if err := writer.Close(); err != nil {
+		return "", errors.Wrap(err, "could not encrypt value")
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext.Bytes()), nil
+}
+
+// isEncryptedConfig reports whether the content of a config file is age encrypted
+func isEncryptedConfig(content []byte) bool {
+	return bytes.HasPrefix(content, []byte(ageHeader)) || bytes.HasPrefix(bytes.TrimSpace(content), []byte(armor.Header))
+}
+
+// decryptConfig decrypts binary or armored age ciphertext with the config key
+func (flagSet *FlagSet) decryptConfig(ciphertext []byte) ([]byte, error) {
+	key := flagSet.configKey
+	if key == "" {
+		key = os.Getenv(configKeyEnv)
+	}
+	if key == "" && flagSet.keyringEnabled {
+		key, _ = keyring.Get(flagSet.appName(), configKeyringKey)
+	}
+	if key == "" {
+		return nil, errors.Errorf("no key provided, set %s", configKeyEnv)
+	}
+	identities, err := age.ParseIdentities(strings.NewReader(key))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid config key")
+	}
+
+	var reader io.Reader = bytes.NewReader(ciphertext)
+	if trimmed := bytes.TrimSpace(ciphertext); bytes.HasPrefix(trimmed, []byte(armor.Header)) {
+		reader = armor.NewReader(bytes.NewReader(trimmed))
+	}
+	plaintext, err := age.Decrypt(reader, identities...)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(plaintext)
+}
+
+// markEncryptedValues wraps the string values found at the encrypted dotted key paths
+func markEncryptedValues(value interface{}, key string, encrypted map[string]struct{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		for itemKey, item := range value {
+			value[itemKey] = markEncryptedValues(item, key+"."+fmt.Sprint(itemKey), encrypted)
+		}
+	case string:
+		if _, ok := encrypted[key]; ok {
+			return encryptedValue(value)
+		}
+	}
+	return value
+}
+
+// decryptConfigValues replaces the encrypted values of the config data with their
+// plaintext, reporting whether any value was encrypted.
+func (flagSet *FlagSet) decryptConfigValues(data map[string]interface{}) (bool, error) {
+	var found bool
+	var decrypt func(value interface{}, key string) (interface{}, error)
+	decrypt = func(value interface{}, key string) (interface{}, error) {
+		switch value := value.(type) {
+		case encryptedValue:
+			found = true
+			ciphertext := []byte(value)
+			if !bytes.HasPrefix(bytes.TrimSpace(ciphertext), []byte(armor.Header)) {
+				decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(value)), ""))
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid encrypted value for %q", key)
+				}
+				ciphertext = decoded
+			}
+			plaintext, err := flagSet.decryptConfig(ciphertext)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not decrypt %q", key)
+			}
+			return string(plaintext), nil
+		case map[interface{}]interface{}:
+			for itemKey, item := range value {
+				decrypted, err := decrypt(item, key+"."+fmt.Sprint(itemKey))
+				if err != nil {
+					return nil, err
+				}
+				value[itemKey] = decrypted
+			}
+		}
+		return value, nil
+	}
+
+	for key, value := range data {
+		decrypted, err := decrypt(value, key)
+		if err != nil {
+			return found, err
+		}
+		data[key] = decrypted
+	}
+	return found, nil
+}
@@ -0,0 +1,110 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func TestEncryptedConfigValues(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.Nil(t, err, "could not generate identity")
+	encrypted, err := EncryptConfigValue("secret", identity.Recipient().String())
+	require.Nil(t, err, "could not encrypt value")
+
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("threads: 10\ntoken: !encrypted "+encrypted), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var threads int
+	var token string
+	flagSet.IntVar(&threads, "threads", 1, "Threads")
+	flagSet.StringVar(&token, "token", "", "API token")
+
+	err = flagSet.MergeConfigFile(configFile)
+	require.NotNil(t, err, "could decrypt value without key")
+
+	flagSet.SetConfigKey(identity.String())
+	err = flagSet.MergeConfigFile(configFile)
+	require.Nil(t, err, "could not merge encrypted config value")
+	require.Equal(t, 10, threads)
+	require.Equal(t, "secret", token)
+
+	tearDown(t.Name())
+}
+
+func TestEncryptedConfigFile(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.Nil(t, err, "could not generate identity")
+	ciphertext := &bytes.Buffer{}
+	armorWriter := armor.NewWriter(ciphertext)
+	writer, err := age.Encrypt(armorWriter, identity.Recipient())
+	require.Nil(t, err, "could not encrypt config")
+	_, err = io.WriteString(writer, "token: secret")
+	require.Nil(t, err, "could not encrypt config")
+	require.Nil(t, writer.Close())
+	require.Nil(t, armorWriter.Close())
+
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, ciphertext.Bytes(), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	os.Setenv(configKeyEnv, identity.String())
+	defer os.Unsetenv(configKeyEnv)
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var token string
+	flagSet.StringVar(&token, "token", "", "API token")
+	err = flagSet.MergeConfigFile(configFile)
+	require.Nil(t, err, "could not merge encrypted config file")
+	require.Equal(t, "secret", token)
+
+	tearDown(t.Name())
+}
+
+func TestConfigKeyFromKeyring(t *testing.T) {
+	keyring.MockInit()
+	identity, err := age.GenerateX25519Identity()
+	require.Nil(t, err, "could not generate identity")
+	encrypted, err := EncryptConfigValue("secret", identity.Recipient().String())
+	require.Nil(t, err, "could not encrypt value")
+
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("token: !encrypted "+encrypted), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var token string
+	flagSet.StringVar(&token, "token", "", "API token")
+	require.Nil(t, flagSet.StoreConfigKey(identity.String()), "could not store config key")
+
+	err = flagSet.MergeConfigFile(configFile)
+	require.NotNil(t, err, "could read config key from keyring without using it")
+
+	flagSet.UseKeyring()
+	err = flagSet.MergeConfigFile(configFile)
+	require.Nil(t, err, "could not decrypt config value with keyring key")
+	require.Equal(t, "secret", token)
+}
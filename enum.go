@@ -0,0 +1,288 @@
+package goflags
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// enumOptions are the values accepted by an enum flag
+type enumOptions struct {
+	allowed         []string
+	aliases         map[string]string
+	caseInsensitive bool
+}
+
+// canonical returns the allowed value matching the input or one of its aliases
+func (options *enumOptions) canonical(input string) (string, error) {
+	for _, allowed := range options.allowed {
+		if options.matches(allowed, input) {
+			return allowed, nil
+		}
+	}
+	for alias, allowed := range options.aliases {
+		if options.matches(alias, input) {
+			return allowed, nil
+		}
+	}
+	return "", errors.Errorf("invalid value %q, allowed values are: %s", input, strings.Join(options.allowed, ", "))
+}
+
+func (options *enumOptions) matches(value, input string) bool {
+	return value == input || (options.caseInsensitive && strings.EqualFold(value, input))
+}
+
+// ValueAlias makes an enum flag accept alias as another spelling of one of its
+// allowed values, so that only the canonical value is ever set.
+//
+// NOTE: ValueAlias panics if value is not one of the allowed values of the flag,
+// unless the FlagSet is in library mode.
+func (flagData *FlagData) ValueAlias(alias, value string) *FlagData {
+	if flagData.enum == nil {
+		flagData.fail(errors.New("value aliases can only be used with enum flags"))
+		return flagData
+	}
+	if _, err := flagData.enum.canonical(value); err != nil {
+		flagData.fail(errors.Errorf("could not alias %q for -%s: %s", alias, flagData.name(), err))
+		return flagData
+	}
+	if flagData.enum.aliases == nil {
+		flagData.enum.aliases = make(map[string]string)
+	}
+	flagData.enum.aliases[alias] = value
+	return flagData
+}
+
+// CaseInsensitive makes an enum flag match its values ignoring their case.
+// Matched values are always stored with the casing they were declared with.
+//
+// NOTE: CaseInsensitive panics if the flag is not an enum flag, unless the
+// FlagSet is in library mode.
+func (flagData *FlagData) CaseInsensitive() *FlagData {
+	if flagData.enum == nil {
+		flagData.fail(errors.New("case insensitive matching can only be used with enum flags"))
+		return flagData
+	}
+	flagData.enum.caseInsensitive = true
+	return flagData
+}
+
+// enumValue is a flag.Value accepting one of the allowed values
+type enumValue struct {
+	field   *string
+	options *enumOptions
+}
+
+func (value *enumValue) String() string {
+	if value.field == nil {
+		return ""
+	}
+	return *value.field
+}
+
+// Type returns the name of the type of the value shown in the usage
+func (value *enumValue) Type() string {
+	return "string"
+}
+
+// Set sets the value if it is one of the allowed values.
+func (value *enumValue) Set(input string) error {
+	canonical, err := value.options.canonical(input)
+	if err != nil {
+		return err
+	}
+	*value.field = canonical
+	return nil
+}
+
+// enumSliceValue is a flag.Value accepting a list of allowed values
+type enumSliceValue struct {
+	field   *[]string
+	options *enumOptions
+}
+
+func (value *enumSliceValue) String() string {
+	if value.field == nil {
+		return ""
+	}
+	return strings.Join(*value.field, " ")
+}
+
+// Type returns the name of the type of the value shown in the usage
+func (value *enumSliceValue) Type() string {
+	return "string[]"
+}
+
+// Set appends the comma separated values if they are all allowed.
+func (value *enumSliceValue) Set(input string) error {
+	items, err := splitSliceValue(input)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		canonical, err := value.options.canonical(item)
+		if err != nil {
+			return err
+		}
+		*value.field = append(*value.field, canonical)
+	}
+	return nil
+}
+
+// EnumVarP adds a enum flag with a shortname and longname accepting one of the allowed values
+func (flagSet *FlagSet) EnumVarP(field *string, long, short, defaultValue string, allowed []string, usage string) *FlagData {
+	*field = defaultValue
+
+	flagData := &FlagData{
+		usage:        usage,
+		short:        short,
+		long:         long,
+		defaultValue: defaultValue,
+		enum:         &enumOptions{allowed: allowed},
+	}
+	return flagSet.addFlag(&enumValue{field: field, options: flagData.enum}, flagData, short, long)
+}
+
+// EnumVar adds a enum flag with a longname accepting one of the allowed values
+func (flagSet *FlagSet) EnumVar(field *string, long, defaultValue string, allowed []string, usage string) *FlagData {
+	*field = defaultValue
+
+	flagData := &FlagData{
+		usage:        usage,
+		long:         long,
+		defaultValue: defaultValue,
+		enum:         &enumOptions{allowed: allowed},
+	}
+	return flagSet.addFlag(&enumValue{field: field, options: flagData.enum}, flagData, long)
+}
+
+// EnumSliceVarP adds a enum slice flag with a shortname and longname accepting a list of the allowed values
+func (flagSet *FlagSet) EnumSliceVarP(field *[]string, long, short string, defaultValue, allowed []string, usage string) *FlagData {
+	*field = append([]string(nil), defaultValue...)
+	defaultSlice := StringSlice(defaultValue)
+
+	flagData := &FlagData{
+		usage:        usage,
+		short:        short,
+		long:         long,
+		defaultValue: defaultSlice.createStringArrayDefaultValue(),
+		enum:         &enumOptions{allowed: allowed},
+	}
+	return flagSet.addFlag(&enumSliceValue{field: field, options: flagData.enum}, flagData, short, long)
+}
+
+// EnumSliceVar adds a enum slice flag with a longname accepting a list of the allowed values
+func (flagSet *FlagSet) EnumSliceVar(field *[]string, long string, defaultValue, allowed []string, usage string) *FlagData {
+	*field = append([]string(nil), defaultValue...)
+	defaultSlice := StringSlice(defaultValue)
+
+	flagData := &FlagData{
+		usage:        usage,
+		long:         long,
+		defaultValue: defaultSlice.createStringArrayDefaultValue(),
+		enum:         &enumOptions{allowed: allowed},
+	}
+	return flagSet.addFlag(&enumSliceValue{field: field, options: flagData.enum}, flagData, long)
+}
+
+// multiChoiceValue is a flag.Value selecting a subset of the allowed values
+type multiChoiceValue struct {
+	enumSliceValue
+	min, max int
+	selected bool
+}
+
+// Set adds the comma separated values to the selection, replacing the default one.
+func (value *multiChoiceValue) Set(input string) error {
+	items, err := splitSliceValue(input)
+	if err != nil {
+		return err
+	}
+	if !value.selected {
+		*value.field = nil
+		value.selected = true
+	}
+	for _, item := range items {
+		canonical, err := value.options.canonical(item)
+		if err != nil {
+			return err
+		}
+		if !sliceContains(*value.field, canonical) {
+			*value.field = append(*value.field, canonical)
+		}
+	}
+	if value.max > 0 && len(*value.field) > value.max {
+		return errors.Errorf("at most %d values can be chosen, got %d (%s)", value.max, len(*value.field), strings.Join(*value.field, ", "))
+	}
+	return nil
+}
+
+// validate checks the number of selected values against the minimum one
+func (value *multiChoiceValue) validate() error {
+	if len(*value.field) < value.min {
+		return errors.Errorf("at least %d of %s must be chosen, got %d", value.min, strings.Join(value.options.allowed, ", "), len(*value.field))
+	}
+	return nil
+}
+
+// MultiChoiceVarP adds a flag with a shortname and longname selecting between min and max of the
+// allowed values. Values provided for the flag replace the default selection.
+func (flagSet *FlagSet) MultiChoiceVarP(field *[]string, long, short string, defaultValue, allowed []string, min, max int, usage string) *FlagData {
+	*field = append([]string(nil), defaultValue...)
+	defaultSlice := StringSlice(defaultValue)
+
+	flagData := &FlagData{
+		usage:        usage,
+		short:        short,
+		long:         long,
+		defaultValue: defaultSlice.createStringArrayDefaultValue(),
+		enum:         &enumOptions{allowed: allowed},
+	}
+	value := &multiChoiceValue{enumSliceValue: enumSliceValue{field: field, options: flagData.enum}, min: min, max: max}
+	return flagSet.addFlag(value, flagData, short, long)
+}
+
+// MultiChoiceVar adds a flag with a longname selecting between min and max of the
+// allowed values. Values provided for the flag replace the default selection.
+func (flagSet *FlagSet) MultiChoiceVar(field *[]string, long string, defaultValue, allowed []string, min, max int, usage string) *FlagData {
+	*field = append([]string(nil), defaultValue...)
+	defaultSlice := StringSlice(defaultValue)
+
+	flagData := &FlagData{
+		usage:        usage,
+		long:         long,
+		defaultValue: defaultSlice.createStringArrayDefaultValue(),
+		enum:         &enumOptions{allowed: allowed},
+	}
+	value := &multiChoiceValue{enumSliceValue: enumSliceValue{field: field, options: flagData.enum}, min: min, max: max}
+	return flagSet.addFlag(value, flagData, long)
+}
+
+// validateChoices checks the selection constraints of the multi choice flags
+func (flagSet *FlagSet) validateChoices() error {
+	var errs Errors
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if key != data.name() {
+			return
+		}
+		currentFlag := flagSet.CommandLine().Lookup(key)
+		if currentFlag == nil {
+			return
+		}
+		if value, ok := unwrapValue(currentFlag.Value).(*multiChoiceValue); ok {
+			if validateErr := value.validate(); validateErr != nil {
+				errs.add(&FlagValueError{Flag: key, Err: validateErr, SeeAlso: data.related})
+			}
+		}
+	})
+	return errs.err()
+}
+
+func sliceContains(slice []string, value string) bool {
+	for _, item := range slice {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package goflags
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnumVar(t *testing.T) {
+	tearDown(t.Name())
+	flag.CommandLine.Init(t.Name(), flag.ContinueOnError)
+	flag.CommandLine.SetOutput(ioutil.Discard)
+
+	flagSet := NewFlagSet()
+	var format, severity string
+	var protocols []string
+	flagSet.EnumVarP(&format, "format", "f", "json", []string{"json", "json-lines", "YAML"}, "Output format")
+	flagSet.EnumVar(&severity, "severity", "info", []string{"info", "High"}, "Severity").CaseInsensitive()
+	flagSet.EnumSliceVar(&protocols, "protocols", []string{"dns"}, []string{"dns", "HTTP", "tls"}, "Protocols").CaseInsensitive()
+
+	require.Equal(t, "json", format, "could not get default value")
+	require.Equal(t, []string{"dns"}, protocols, "could not get default value")
+
+	err := flag.CommandLine.Parse([]string{"-f", "YAML", "-severity", "HIGH", "-protocols", "http, TLS"})
+	require.Nil(t, err, "could not parse enum flags")
+	require.Equal(t, "YAML", format)
+	require.Equal(t, "High", severity, "could not canonicalize value")
+	require.Equal(t, []string{"dns", "HTTP", "tls"}, protocols, "could not canonicalize values")
+
+	err = flag.CommandLine.Parse([]string{"-f", "yaml"})
+	require.NotNil(t, err, "could match case sensitive value")
+	require.Contains(t, err.Error(), "json, json-lines, YAML")
+
+	err = flag.CommandLine.Parse([]string{"-protocols", "dns,ftp"})
+	require.NotNil(t, err, "could parse invalid slice value")
+
+	flag.CommandLine.SetOutput(ioutil.Discard)
+	flagSet.usageFunc()
+
+	tearDown(t.Name())
+}
+
+func TestEnumValueAlias(t *testing.T) {
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+
+	var format string
+	var severities []string
+	flagSet.EnumVar(&format, "format", "json", []string{"json", "json-lines"}, "Output format").ValueAlias("jsonl", "json-lines")
+	flagSet.EnumSliceVar(&severities, "severity", nil, []string{"info", "critical"}, "Severities").ValueAlias("crit", "critical").CaseInsensitive()
+
+	err := flag.CommandLine.Parse([]string{"-format", "jsonl", "-severity", "CRIT,info"})
+	require.Nil(t, err, "could not parse enum aliases")
+	require.Equal(t, "json-lines", format)
+	require.Equal(t, []string{"critical", "info"}, severities)
+
+	require.Panics(t, func() {
+		flagSet.EnumVar(&format, "other", "json", []string{"json"}, "Other format").ValueAlias("yml", "yaml")
+	})
+
+	tearDown(t.Name())
+}
+
+func TestMultiChoiceVar(t *testing.T) {
+	tearDown(t.Name())
+	flag.CommandLine.Init(t.Name(), flag.ContinueOnError)
+	flag.CommandLine.SetOutput(ioutil.Discard)
+
+	flagSet := NewFlagSet()
+	var protocols []string
+	flagSet.MultiChoiceVarP(&protocols, "protocols", "p", []string{"dns"}, []string{"dns", "http", "tls"}, 1, 2, "Protocols")
+
+	err := flag.CommandLine.Parse([]string{"-p", "http", "-protocols", "tls,http"})
+	require.Nil(t, err, "could not parse choices")
+	require.Equal(t, []string{"http", "tls"}, protocols, "could not replace default selection")
+	require.Nil(t, flagSet.validateChoices())
+
+	err = flag.CommandLine.Parse([]string{"-p", "dns"})
+	require.NotNil(t, err, "could choose more than the maximum")
+	require.Contains(t, err.Error(), "at most 2 values")
+
+	protocols = nil
+	err = flagSet.validateChoices()
+	require.NotNil(t, err, "could choose less than the minimum")
+	require.Contains(t, err.Error(), "-protocols")
+
+	tearDown(t.Name())
+}
@@ -0,0 +1,189 @@
+package goflags
+
+import (
+	"bufio"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LoadDotEnv loads a .env file of KEY=value lines during Parse, whose values are
+// used for the flags bound to environment variables not set in the environment,
+// or overriding the environment when DotEnvOverride is set.
+//
+// Missing files are ignored, and files loaded first take precedence over later ones.
+// The variable and file supplying the value of a flag are reported by Source.
+func (flagSet *FlagSet) LoadDotEnv(path string) {
+	flagSet.dotEnvFiles = append(flagSet.dotEnvFiles, path)
+}
+
+// Env binds the flag to more environment variables, tried in order after the ones
+// already bound, whose value is used as default value of the flag during Parse.
+// The first variable set in the environment wins, before the loaded .env files.
+func (flagData *FlagData) Env(names ...string) *FlagData {
+	flagData.envNames = append(flagData.envNames, names...)
+	return flagData
+}
+
+// dotEnvValue is a variable read from a .env file
+type dotEnvValue struct {
+	value string
+	file  string
+}
+
+// envLookup looks up an environment variable, returning the .env file defining it if any
+type envLookup func(name string) (value, file string, ok bool)
+
+// applyEnv sets the default value of the flags bound to environment variables
+// from the environment and the loaded .env files, in the order set by DotEnvOverride.
+func (flagSet *FlagSet) applyEnv() error {
+	values, err := readDotEnvFiles(flagSet.dotEnvFiles)
+	if err != nil {
+		return err
+	}
+	lookups := []envLookup{
+		func(name string) (string, string, bool) {
+			value, ok := os.LookupEnv(name)
+			return value, "", ok
+		},
+		func(name string) (string, string, bool) {
+			value, ok := values[name]
+			return value.value, value.file, ok
+		},
+	}
+	if flagSet.DotEnvOverride {
+		lookups[0], lookups[1] = lookups[1], lookups[0]
+	}
+
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if err != nil || len(data.envNames) == 0 || key != data.name() {
+			return
+		}
+		for _, lookup := range lookups {
+			if source, value, ok := lookupEnvNames(data.envNames, lookup); ok {
+				if err = flagSet.setDefaultValue(data, value, source.Name); err == nil {
+					flagSet.setSource(key, source)
+				}
+				return
+			}
+		}
+	})
+	return err
+}
+
+// lookupEnvNames returns the source and value of the first environment variable found with lookup
+func lookupEnvNames(names []string, lookup envLookup) (Source, string, bool) {
+	for _, name := range names {
+		if value, file, ok := lookup(name); ok {
+			return Source{Kind: SourceEnv, Name: name, File: file}, value, true
+		}
+	}
+	return Source{}, "", false
+}
+
+// setDefaultValue replaces the default value of a flag after its registration
+// with a value read from source, replacing the default items of slice flags.
+func (flagSet *FlagSet) setDefaultValue(data *FlagData, value, source string) error {
+	currentFlag := flagSet.CommandLine().Lookup(data.name())
+	if currentFlag == nil {
+		return nil
+	}
+	flagValue := unwrapValue(currentFlag.Value)
+	if err := replaceValue(flagValue, value); err != nil {
+		return errors.Wrapf(err, "invalid value %q for -%s from %s", value, data.name(), source)
+	}
+	if choice, ok := flagValue.(*multiChoiceValue); ok {
+		choice.selected = false // values provided on the command line replace the default ones
+	}
+
+	for _, name := range []string{data.short, data.long} {
+		if namedFlag := flagSet.CommandLine().Lookup(name); namedFlag != nil {
+			namedFlag.DefValue = flagValue.String()
+		}
+	}
+	if slice, ok := flagValue.(*StringSlice); ok {
+		data.defaultValue = slice.createStringArrayDefaultValue()
+	} else {
+		data.defaultValue = flagValue.String()
+	}
+	return nil
+}
+
+// replaceValue sets the value of a flag, replacing the current items of slice flags
+func replaceValue(value flag.Value, input string) error {
+	switch value := value.(type) {
+	case *StringSlice:
+		*value = nil
+	case *enumSliceValue:
+		*value.field = nil
+	case *multiChoiceValue:
+		value.selected = false
+	}
+	return value.Set(input)
+}
+
+// readDotEnvFiles reads the variables of the .env files, the first file defining a variable winning
+func readDotEnvFiles(paths []string) (map[string]dotEnvValue, error) {
+	values := make(map[string]dotEnvValue)
+	for _, path := range paths {
+		fileValues, err := readDotEnvFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range fileValues {
+			if _, ok := values[key]; !ok {
+				values[key] = dotEnvValue{value: value, file: path}
+			}
+		}
+	}
+	return values, nil
+}
+
+// readDotEnvFile reads the KEY=value lines of a .env file
+func readDotEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open .env file")
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.Errorf("invalid .env line %d in %s: %s", lineNumber, path, line)
+		}
+		values[strings.TrimSpace(parts[0])] = parseDotEnvValue(strings.TrimSpace(parts[1]))
+	}
+	return values, scanner.Err()
+}
+
+// parseDotEnvValue unquotes a .env value, removing the inline comments of unquoted ones
+func parseDotEnvValue(value string) string {
+	switch {
+	case len(value) >= 2 && strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\""):
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		return value[1 : len(value)-1]
+	case len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'"):
+		return value[1 : len(value)-1]
+	}
+	if index := strings.Index(value, " #"); index != -1 {
+		value = strings.TrimSpace(value[:index])
+	}
+	return value
+}
@@ -0,0 +1,208 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDotEnv(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	dotEnv := filepath.Join(tempDir, ".env")
+	err = ioutil.WriteFile(dotEnv, []byte(`
+# test values
+GOFLAGS_TEST_TOKEN="dotenv token"
+export GOFLAGS_TEST_PROXY=http://dotenv:8080 # inline comment
+GOFLAGS_TEST_REAL=dotenv
+`), os.ModePerm)
+	require.Nil(t, err, "could not write .env file")
+	err = ioutil.WriteFile(filepath.Join(tempDir, "second.env"), []byte("GOFLAGS_TEST_TOKEN=second\nGOFLAGS_TEST_OTHER=second"), os.ModePerm)
+	require.Nil(t, err, "could not write .env file")
+
+	os.Setenv("GOFLAGS_TEST_REAL", "environment")
+	defer os.Unsetenv("GOFLAGS_TEST_REAL")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.LoadDotEnv(dotEnv)
+	flagSet.LoadDotEnv(filepath.Join(tempDir, "missing.env"))
+	flagSet.LoadDotEnv(filepath.Join(tempDir, "second.env"))
+
+	var token, proxy, real, other, cli string
+	flagSet.StringVarEnv(&token, "token", "t", "", "GOFLAGS_TEST_TOKEN", "API token")
+	flagSet.StringVarEnv(&proxy, "proxy", "p", "", "GOFLAGS_TEST_PROXY", "Proxy")
+	flagSet.StringVarEnv(&real, "real", "r", "", "GOFLAGS_TEST_REAL", "Real env value")
+	flagSet.StringVarEnv(&other, "other", "o", "", "GOFLAGS_TEST_OTHER", "Other value")
+	flagSet.StringVarEnv(&cli, "cli", "c", "", "GOFLAGS_TEST_OTHER", "Cli value")
+
+	err = flagSet.applyEnv()
+	require.Nil(t, err, "could not apply .env files")
+	err = flag.CommandLine.Parse([]string{"-cli", "cli"})
+	require.Nil(t, err, "could not parse flags")
+
+	require.Equal(t, "dotenv token", token)
+	require.Equal(t, "http://dotenv:8080", proxy)
+	require.Equal(t, "environment", real, "could not prefer the environment")
+	require.Equal(t, "second", other)
+	require.Equal(t, "cli", cli, "could not prefer the cli")
+	require.Equal(t, "dotenv token", flag.CommandLine.Lookup("t").DefValue, "could not update default value")
+
+	tearDown(t.Name())
+}
+
+func TestEnvNames(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	dotEnv := filepath.Join(tempDir, ".env")
+	err = ioutil.WriteFile(dotEnv, []byte("GOFLAGS_TEST_NEW_TIMEOUT=30"), os.ModePerm)
+	require.Nil(t, err, "could not write .env file")
+
+	os.Setenv("GOFLAGS_TEST_LEGACY_PROXY", "http://legacy:8080")
+	defer os.Unsetenv("GOFLAGS_TEST_LEGACY_PROXY")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.LoadDotEnv(dotEnv)
+
+	var proxy string
+	var timeout int
+	flagSet.StringVarEnv(&proxy, "proxy", "p", "", "GOFLAGS_TEST_NEW_PROXY", "Proxy").Env("GOFLAGS_TEST_LEGACY_PROXY")
+	flagSet.IntVarP(&timeout, "timeout", "t", 10, "Timeout").Env("GOFLAGS_TEST_LEGACY_TIMEOUT", "GOFLAGS_TEST_NEW_TIMEOUT")
+
+	err = flagSet.applyEnv()
+	require.Nil(t, err, "could not apply env")
+	require.Equal(t, "http://legacy:8080", proxy, "could not use legacy env name")
+	require.Equal(t, 30, timeout, "could not use .env value")
+
+	os.Setenv("GOFLAGS_TEST_NEW_PROXY", "http://new:8080")
+	defer os.Unsetenv("GOFLAGS_TEST_NEW_PROXY")
+	err = flagSet.applyEnv()
+	require.Nil(t, err, "could not apply env")
+	require.Equal(t, "http://new:8080", proxy, "could not prefer first env name")
+
+	tearDown(t.Name())
+}
+
+func TestUsageSources(t *testing.T) {
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+
+	var proxy, password string
+	var timeout int
+	flagSet.StringVarEnv(&proxy, "proxy", "p", "", "MYTOOL_PROXY", "Proxy").Env("HTTP_PROXY")
+	flagSet.IntVar(&timeout, "timeout", 10, "Timeout")
+	flagSet.PasswordVar(&password, "password", "Password")
+
+	usage := &bytes.Buffer{}
+	flag.CommandLine.SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "Proxy [env: MYTOOL_PROXY, HTTP_PROXY]\n")
+	require.NotContains(t, usage.String(), "[config:")
+
+	usage.Reset()
+	flagSet.UsageConfigKeys = true
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "Proxy [env: MYTOOL_PROXY, HTTP_PROXY] [config: proxy]\n")
+	require.Contains(t, usage.String(), "Timeout (default 10) [config: timeout]\n")
+	require.NotContains(t, usage.String(), "[config: password]")
+
+	tearDown(t.Name())
+}
+
+func TestEnvFlagTypes(t *testing.T) {
+	env := map[string]string{
+		"GOFLAGS_TEST_VERBOSE": "true",
+		"GOFLAGS_TEST_THREADS": "25",
+		"GOFLAGS_TEST_TIMEOUT": "1m30s",
+		"GOFLAGS_TEST_TARGETS": "a.com,b.com",
+		"GOFLAGS_TEST_FORMATS": "json",
+		"GOFLAGS_TEST_KEY":     "cafe",
+	}
+	for name, value := range env {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var verbose bool
+	var threads int
+	var timeout time.Duration
+	var targets StringSlice
+	var formats []string
+	var key []byte
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output").Env("GOFLAGS_TEST_VERBOSE")
+	flagSet.IntVar(&threads, "threads", 10, "Threads").Env("GOFLAGS_TEST_THREADS")
+	flagSet.DurationVar(&timeout, "timeout", time.Second, "Timeout").Env("GOFLAGS_TEST_TIMEOUT")
+	flagSet.StringSliceVar(&targets, "targets", []string{"default.com"}, "Targets").Env("GOFLAGS_TEST_TARGETS")
+	flagSet.MultiChoiceVar(&formats, "formats", []string{"yaml"}, []string{"json", "yaml", "csv"}, 1, 2, "Formats").Env("GOFLAGS_TEST_FORMATS")
+	flagSet.HexBytesVar(&key, "key", nil, 0, 0, "Key").Env("GOFLAGS_TEST_KEY")
+
+	err := flagSet.applyEnv()
+	require.Nil(t, err, "could not apply env")
+	require.True(t, verbose)
+	require.Equal(t, 25, threads)
+	require.Equal(t, 90*time.Second, timeout)
+	require.Equal(t, StringSlice{"a.com", "b.com"}, targets, "could not replace default items")
+	require.Equal(t, []byte{0xca, 0xfe}, key)
+	require.Equal(t, "1m30s", flag.CommandLine.Lookup("timeout").DefValue)
+
+	err = flag.CommandLine.Parse([]string{"-formats", "csv"})
+	require.Nil(t, err, "could not parse flags")
+	require.Equal(t, []string{"csv"}, formats, "could not replace env selection")
+
+	tearDown(t.Name())
+	flagSet = NewFlagSet()
+	flagSet.IntVar(&threads, "threads", 10, "Threads").Env("GOFLAGS_TEST_TIMEOUT")
+	err = flagSet.applyEnv()
+	require.NotNil(t, err, "could apply malformed env value")
+	require.Contains(t, err.Error(), `invalid value "1m30s" for -threads from GOFLAGS_TEST_TIMEOUT`)
+
+	tearDown(t.Name())
+}
+
+func TestDotEnvOverride(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	dotEnv := filepath.Join(tempDir, ".env")
+	err = ioutil.WriteFile(dotEnv, []byte("GOFLAGS_TEST_PROXY=http://dotenv:8080"), os.ModePerm)
+	require.Nil(t, err, "could not write .env file")
+
+	os.Setenv("GOFLAGS_TEST_PROXY", "http://environment:8080")
+	defer os.Unsetenv("GOFLAGS_TEST_PROXY")
+
+	for _, override := range []bool{false, true} {
+		tearDown(t.Name())
+		flagSet := NewFlagSet()
+		flagSet.LoadDotEnv(dotEnv)
+		flagSet.DotEnvOverride = override
+
+		var proxy string
+		flagSet.StringVar(&proxy, "proxy", "", "Proxy").Env("GOFLAGS_TEST_PROXY")
+		err = flagSet.applyEnv()
+		require.Nil(t, err, "could not apply env")
+
+		if override {
+			require.Equal(t, "http://dotenv:8080", proxy)
+			require.Equal(t, Source{Kind: SourceEnv, Name: "GOFLAGS_TEST_PROXY", File: dotEnv}, flagSet.Source("proxy"))
+		} else {
+			require.Equal(t, "http://environment:8080", proxy)
+			require.Equal(t, Source{Kind: SourceEnv, Name: "GOFLAGS_TEST_PROXY"}, flagSet.Source("proxy"))
+		}
+	}
+
+	tearDown(t.Name())
+}
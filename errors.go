@@ -0,0 +1,141 @@
+package goflags
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// defaultExitCode is the exit code used on parse failure, as the flag package does
+const defaultExitCode = 2
+
+// exit terminates the program, replaced in tests
+var exit = os.Exit
+
+// Errors is a list of errors reported together
+type Errors []error
+
+func (errs Errors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// add appends an error to the list, flattening lists of errors
+func (errs *Errors) add(err error) {
+	switch err := err.(type) {
+	case nil:
+	case Errors:
+		*errs = append(*errs, err...)
+	default:
+		*errs = append(*errs, err)
+	}
+}
+
+// err returns the list as an error, or nil if it is empty
+func (errs Errors) err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// SetErrorHandling sets how Parse handles a failure: ContinueOnError returns
+// the error, ExitOnError prints it and exits with the exit code, while
+// PanicOnError panics with it. It applies to the errors of the command line
+// arguments as well as to the ones found once they are parsed.
+func (flagSet *FlagSet) SetErrorHandling(errorHandling flag.ErrorHandling) {
+	flagSet.errorHandling = &errorHandling
+}
+
+// SetExitCode sets the exit code used when Parse fails with ExitOnError, 2 by default
+func (flagSet *FlagSet) SetExitCode(code int) {
+	flagSet.exitCode = code
+}
+
+// handleError handles a Parse failure according to the error handling of the FlagSet,
+// errors of the command line arguments which were printed by the flag package being
+// handled like the flag package would do if no error handling was set.
+func (flagSet *FlagSet) handleError(err error, printed bool) error {
+	if err == nil {
+		return nil
+	}
+	errorHandling := flagSet.errorHandling
+	if errorHandling == nil && printed {
+		errorHandling = flagSet.parseErrorHandling
+	}
+	if errorHandling == nil {
+		return err
+	}
+	switch *errorHandling {
+	case flag.ExitOnError:
+		if err == flag.ErrHelp || err == ErrVersion || err == ErrCompletion {
+			exit(0)
+			return err
+		}
+		if !printed {
+			fmt.Fprintln(flagSet.CommandLine().Output(), err)
+		}
+		code := flagSet.exitCode
+		if code == 0 {
+			code = defaultExitCode
+		}
+		exit(code)
+	case flag.PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+// OnUsageError sets a function called by Parse with every error found in the
+// arguments or the values of the flags, and the argument, flag or config key it
+// was found in, before the error is handled.
+func (flagSet *FlagSet) OnUsageError(callback func(arg string, err error)) {
+	flagSet.onUsageError = callback
+}
+
+// reportUsageError calls the usage error callback with the errors
+func (flagSet *FlagSet) reportUsageError(err error) {
+	if flagSet.onUsageError == nil || err == nil || err == flag.ErrHelp || err == ErrVersion || err == ErrCompletion {
+		return
+	}
+	if errs, ok := err.(Errors); ok {
+		for _, err := range errs {
+			flagSet.reportUsageError(err)
+		}
+		return
+	}
+	flagSet.onUsageError(usageErrorArg(err), err)
+}
+
+// parseErrorPrefixes are the prefixes of the errors of the flag package followed by the offending argument
+var parseErrorPrefixes = []string{"flag provided but not defined: ", "bad flag syntax: ", "flag needs an argument: "}
+
+// usageErrorArg returns the argument, flag or config key an error was found in
+func usageErrorArg(err error) string {
+	switch err := err.(type) {
+	case *FlagValueError:
+		return "-" + err.Flag
+	case *ConfigValueError:
+		return err.Key
+	case *unknownCommandError:
+		return err.name
+	}
+	message := err.Error()
+	for _, prefix := range parseErrorPrefixes {
+		if strings.HasPrefix(message, prefix) {
+			return strings.TrimPrefix(message, prefix)
+		}
+	}
+	if match := invalidFlagValueRegex.FindStringSubmatch(message); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// invalidFlagValueRegex matches the errors of the flag package for invalid values
+var invalidFlagValueRegex = regexp.MustCompile(`^invalid (?:boolean )?value .* for flag (-\S+): `)
@@ -0,0 +1,174 @@
+package goflags
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrors(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("threads: ten\nretries: 3"), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	flagSet := newConstraintsFlagSet(t.Name())
+	flagSet.StrictConfig = true
+	flagSet.SetConfigSearchPaths(configFile)
+	var threads, rate int
+	var target, list, format string
+	flagSet.IntVar(&threads, "threads", 10, "Threads")
+	flagSet.IntVar(&rate, "rate", 100, "Rate").WithMax(1000)
+	flagSet.StringVar(&target, "target", "", "Target").Required()
+	flagSet.StringVar(&list, "list", "", "Target list")
+	flagSet.EnumVar(&format, "format", "json", []string{"json", "yaml"}, "Output format")
+	flagSet.MarkOneRequired("target", "list")
+	flagSet.SetValidationFunc(func(flagSet *FlagSet) error {
+		return errors.New("invalid combination")
+	})
+
+	os.Args = []string{os.Args[0], "-rate", "5000"}
+	err = flagSet.Parse()
+	require.NotNil(t, err, "could parse invalid flags")
+
+	errs, ok := err.(Errors)
+	require.True(t, ok, "could not get aggregated errors")
+	require.Len(t, errs, 6)
+	require.Equal(t, "threads", errs[0].(*ConfigValueError).Key)
+	require.Equal(t, configFile+":2: invalid value for \"retries\": unknown flag", errs[1].Error())
+	require.Equal(t, "missing required flags: -target", errs[2].Error())
+	require.Contains(t, errs[3].Error(), "one of -target, -list is required")
+	require.Equal(t, "invalid value for -rate: must be at most 1000", errs[4].Error())
+	require.Equal(t, "invalid combination", errs[5].Error())
+
+	tearDown(t.Name())
+}
+
+func TestLibraryMode(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.LibraryMode = true
+
+	var threads, retries int
+	var format, id string
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads")
+	flagSet.IntVarP(&retries, "retries", "t", 1, "Retries")
+	flagSet.IntVar(&retries, "", 1, "Retries")
+	flagSet.EnumVar(&format, "format", "json", []string{"json", "yaml"}, "Output format").ValueAlias("yml", "xml")
+	flagSet.StringVar(&id, "id", "", "Identifier").Pattern("[").ValueAlias("a", "b").CaseInsensitive()
+	require.NotPanics(t, func() { flagSet.MarkRequired("missing") })
+
+	err := flagSet.ParseArgs(nil)
+	require.NotNil(t, err, "could parse invalid registrations")
+	require.Equal(t, `flag redefined: t
+flag must have a name
+could not alias "yml" for -format: invalid value "xml", allowed values are: json, yaml
+invalid pattern for -id: error parsing regexp: missing closing ]: `+"`[`"+`
+value aliases can only be used with enum flags
+case insensitive matching can only be used with enum flags
+unknown flag -missing in constraint`, err.Error())
+	require.Nil(t, flagSet.CommandLine().Lookup("retries"), "could register invalid flag")
+
+	flagSet = NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads")
+	require.Panics(t, func() { flagSet.IntVarP(&retries, "retries", "t", 1, "Retries") })
+}
+
+func TestErrorHandling(t *testing.T) {
+	defer func() { exit = os.Exit }()
+	var exitCode int
+	exit = func(code int) { exitCode = code }
+
+	newFlagSet := func(errorHandling flag.ErrorHandling) *FlagSet {
+		flagSet := NewScopedFlagSet(t.Name(), flag.ExitOnError)
+		flagSet.SetConfigFilePath("")
+		flagSet.CommandLine().SetOutput(ioutil.Discard)
+		flagSet.SetErrorHandling(errorHandling)
+		var target string
+		flagSet.StringVar(&target, "target", "", "Target").Required()
+		return flagSet
+	}
+
+	flagSet := newFlagSet(flag.ContinueOnError)
+	require.NotNil(t, flagSet.ParseArgs([]string{"-unknown"}), "could parse unknown flag")
+	require.NotNil(t, flagSet.ParseArgs(nil), "could parse without required flag")
+	require.Zero(t, exitCode, "could exit with ContinueOnError")
+
+	flagSet = newFlagSet(flag.ExitOnError)
+	flagSet.SetExitCode(3)
+	_ = flagSet.ParseArgs([]string{"-unknown"})
+	require.Equal(t, 3, exitCode, "could not exit on unknown flag")
+	exitCode = 0
+	_ = flagSet.ParseArgs(nil)
+	require.Equal(t, 3, exitCode, "could not exit without required flag")
+	exitCode = -1
+	_ = flagSet.ParseArgs([]string{"-h"})
+	require.Zero(t, exitCode, "could not exit successfully on help")
+
+	flagSet = newFlagSet(flag.PanicOnError)
+	require.Panics(t, func() { _ = flagSet.ParseArgs(nil) })
+}
+
+func TestOnUsageError(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.CommandLine().SetOutput(ioutil.Discard)
+	var reported []string
+	flagSet.OnUsageError(func(arg string, err error) {
+		reported = append(reported, arg)
+	})
+
+	var threads int
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads").WithMin(1)
+	scan := flagSet.NewCommand("scan", "Scan targets")
+	scan.CommandLine().SetOutput(ioutil.Discard)
+	var rate int
+	scan.IntVar(&rate, "rate", 10, "Rate")
+
+	require.NotNil(t, flagSet.ParseArgs([]string{"-unknown"}))
+	require.NotNil(t, flagSet.ParseArgs([]string{"-t", "ten"}))
+	require.NotNil(t, flagSet.ParseArgs([]string{"-t", "0"}))
+	threads = 1
+	require.NotNil(t, flagSet.ParseArgs([]string{"report"}))
+	require.NotNil(t, flagSet.ParseArgs([]string{"scan", "-rate"}))
+	require.Equal(t, []string{"-unknown", "-t", "-threads", "report", "-rate"}, reported)
+
+	reported = nil
+	require.Equal(t, flag.ErrHelp, flagSet.ParseArgs([]string{"-h"}))
+	require.Empty(t, reported, "could report help request")
+}
+
+func TestParseErrorsSkipSideEffects(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	stdin := strings.NewReader("payload")
+	flagSet.stdin = stdin
+	var input string
+	var threads, retries int
+	flagSet.StringVar(&input, "input", "", "Input").AllowStdin()
+	flagSet.IntVar(&threads, "threads", 1, "Threads").WithMax(10)
+	flagSet.IntVar(&retries, "retries", 1, "Retries").Env("GOFLAGS_TEST_RETRIES")
+	flagSet.NewCommand("scan", "Scan the targets")
+
+	err := flagSet.ParseArgs([]string{"-threads", "20", "scan"})
+	require.NotNil(t, err, "could parse invalid flags")
+	require.Equal(t, "invalid value for -threads: must be at most 10", err.Error())
+	require.Nil(t, flagSet.Command(), "command was dispatched with invalid flags")
+
+	os.Setenv("GOFLAGS_TEST_RETRIES", "ten")
+	defer os.Unsetenv("GOFLAGS_TEST_RETRIES")
+	flagSet.Reset()
+	err = flagSet.ParseArgs([]string{"-input", "-", "-threads", "20"})
+	require.NotNil(t, err, "could parse invalid env value")
+	require.Len(t, err.(Errors), 2, "could not aggregate the checks with the env error")
+	require.Equal(t, 7, stdin.Len(), "stdin was consumed with an invalid env value")
+}
@@ -0,0 +1,32 @@
+package goflags
+
+import (
+	"fmt"
+	"io"
+)
+
+// example is an invocation of the program shown in the usage
+type example struct {
+	description string
+	command     string
+}
+
+// AddExample adds an example invocation of the program with its description,
+// shown in the Examples section at the end of the usage in the order they are added.
+func (flagSet *FlagSet) AddExample(description, command string) {
+	flagSet.examples = append(flagSet.examples, example{description: description, command: command})
+}
+
+// writeUsageExamples writes the Examples section of the usage
+func (flagSet *FlagSet) writeUsageExamples(output io.Writer) {
+	if len(flagSet.examples) == 0 {
+		return
+	}
+	fmt.Fprintf(output, "\nExamples:\n")
+	for i, example := range flagSet.examples {
+		if i > 0 {
+			fmt.Fprintln(output)
+		}
+		fmt.Fprintf(output, "  %s\n    %s\n", example.description, example.command)
+	}
+}
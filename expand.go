@@ -0,0 +1,147 @@
+package goflags
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// referenceLookup returns the value of a ${NAME} reference, or false
+// if the reference should be kept as is.
+type referenceLookup func(name string) (string, bool, error)
+
+// expandReferences replaces the ${NAME} references in a value using lookup.
+//
+// When unescape is true a $${ sequence is replaced with a literal ${,
+// otherwise it is kept untouched for a later expansion.
+func expandReferences(value string, unescape bool, lookup referenceLookup) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+
+	builder := &strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], "$${"):
+			if unescape {
+				builder.WriteString("${")
+			} else {
+				builder.WriteString("$${")
+			}
+			i += 2
+		case strings.HasPrefix(value[i:], "${"):
+			end := strings.IndexByte(value[i+2:], '}')
+			if end == -1 {
+				builder.WriteString(value[i:])
+				return builder.String(), nil
+			}
+			reference := value[i : i+3+end]
+			resolved, ok, err := lookup(value[i+2 : i+2+end])
+			if err != nil {
+				return "", err
+			}
+			if !ok {
+				resolved = reference
+			}
+			builder.WriteString(resolved)
+			i += 2 + end
+		default:
+			builder.WriteByte(value[i])
+		}
+	}
+	return builder.String(), nil
+}
+
+// expandEnv replaces the ${NAME} references in a value with the value of
+// the NAME environment variable. A $${ sequence is kept as a literal ${.
+//
+// When interpolation is enabled references to flags are left to interpolateValues.
+func (flagSet *FlagSet) expandEnv(value string) string {
+	expanded, _ := expandReferences(value, !flagSet.Interpolate, func(name string) (string, bool, error) {
+		if _, ok := flagSet.flagKeys.values[name]; ok && flagSet.Interpolate {
+			return "", false, nil
+		}
+		return os.Getenv(name), true, nil
+	})
+	return expanded
+}
+
+// expandConfigEnv expands the environment variable references of a config file value.
+// Values are already expanded when they are set if ExpandEnv is enabled.
+func (flagSet *FlagSet) expandConfigEnv(value string) string {
+	if !flagSet.ExpandConfigEnv || flagSet.ExpandEnv {
+		return value
+	}
+	return flagSet.expandEnv(value)
+}
+
+// interpolator resolves the references between flag values
+type interpolator struct {
+	flagSet  *FlagSet
+	resolved map[string]string
+}
+
+// interpolateValues replaces the ${name} references to other flags in the
+// flag values with their final value, once all the value sources are merged.
+func (flagSet *FlagSet) interpolateValues() error {
+	resolver := &interpolator{flagSet: flagSet, resolved: make(map[string]string)}
+
+	var err error
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if err != nil || key != data.name() {
+			return
+		}
+		_, err = resolver.resolve(key, nil)
+	})
+	return err
+}
+
+// resolve interpolates the value of a flag, returning its final string value
+func (resolver *interpolator) resolve(name string, stack []string) (string, error) {
+	if resolved, ok := resolver.resolved[name]; ok {
+		return resolved, nil
+	}
+	for i, item := range stack {
+		if item == name {
+			return "", errors.Errorf("cyclic flag reference: %s", strings.Join(append(stack[i:], name), " -> "))
+		}
+	}
+	stack = append(stack, name)
+
+	lookup := func(reference string) (string, bool, error) {
+		data, ok := resolver.flagSet.flagKeys.values[reference]
+		if !ok {
+			return "", false, nil
+		}
+		resolved, err := resolver.resolve(data.name(), stack)
+		return resolved, true, err
+	}
+
+	currentFlag := resolver.flagSet.CommandLine().Lookup(name)
+	if currentFlag == nil {
+		return "", nil
+	}
+	value := unwrapValue(currentFlag.Value)
+
+	if stringSlice, ok := value.(*StringSlice); ok {
+		for i, item := range *stringSlice {
+			expanded, err := expandReferences(item, true, lookup)
+			if err != nil {
+				return "", err
+			}
+			(*stringSlice)[i] = expanded
+		}
+	} else if current := value.String(); strings.Contains(current, "${") {
+		expanded, err := expandReferences(current, true, lookup)
+		if err != nil {
+			return "", err
+		}
+		if err := value.Set(expanded); err != nil {
+			return "", errors.Wrapf(err, "could not set interpolated value for -%s", name)
+		}
+	}
+
+	resolver.resolved[name] = value.String()
+	return resolver.resolved[name], nil
+}
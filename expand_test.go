@@ -0,0 +1,134 @@
+package goflags
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnv(t *testing.T) {
+	os.Setenv("GOFLAGS_TEST_VALUE", "value")
+	defer os.Unsetenv("GOFLAGS_TEST_VALUE")
+
+	values := map[string]string{
+		"plain":                                          "plain",
+		"${GOFLAGS_TEST_VALUE}":                          "value",
+		"a/${GOFLAGS_TEST_VALUE}/b":                      "a/value/b",
+		"${GOFLAGS_TEST_MISSING}":                        "",
+		"$${GOFLAGS_TEST_VALUE}":                         "${GOFLAGS_TEST_VALUE}",
+		"$GOFLAGS_TEST_VALUE":                            "$GOFLAGS_TEST_VALUE",
+		"${GOFLAGS_TEST_VALUE":                           "${GOFLAGS_TEST_VALUE",
+		"${GOFLAGS_TEST_VALUE}$${}${GOFLAGS_TEST_VALUE}": "value${}value",
+	}
+	flagSet := NewFlagSet()
+	for input, expected := range values {
+		require.Equal(t, expected, flagSet.expandEnv(input), "could not expand %q", input)
+	}
+}
+
+func TestExpandEnvFlagValues(t *testing.T) {
+	os.Setenv("GOFLAGS_TEST_VALUE", "value")
+	defer os.Unsetenv("GOFLAGS_TEST_VALUE")
+
+	flagSet := NewFlagSet()
+	flagSet.ExpandEnv = true
+
+	var data, data2 string
+	var data3 StringSlice
+	flagSet.StringVarP(&data, "output", "o", "", "Output file")
+	flagSet.StringVar(&data2, "config-value", "", "Config value")
+	flagSet.StringSliceVar(&data3, "slice", nil, "Slice value")
+
+	err := flag.CommandLine.Parse([]string{"-o", "${GOFLAGS_TEST_VALUE}/out.json", "-slice", "a,${GOFLAGS_TEST_VALUE}"})
+	require.Nil(t, err, "could not parse flags")
+
+	err = ioutil.WriteFile("test.yaml", []byte("config-value: $${literal}-${GOFLAGS_TEST_VALUE}"), os.ModePerm)
+	require.Nil(t, err, "could not write temporary config")
+	defer os.Remove("test.yaml")
+
+	err = flagSet.MergeConfigFile("test.yaml")
+	require.Nil(t, err, "could not merge temporary config")
+
+	require.Equal(t, "value/out.json", data, "could not expand cli value")
+	require.Equal(t, "${literal}-value", data2, "could not expand config value")
+	require.Equal(t, StringSlice{"a", "value"}, data3, "could not expand slice value")
+
+	tearDown(t.Name())
+}
+
+func TestInterpolateValues(t *testing.T) {
+	os.Setenv("GOFLAGS_TEST_VALUE", "value")
+	defer os.Unsetenv("GOFLAGS_TEST_VALUE")
+
+	flagSet := NewFlagSet()
+	flagSet.ExpandEnv = true
+	flagSet.Interpolate = true
+
+	var project, output, literal string
+	var paths StringSlice
+	flagSet.StringVarP(&project, "project", "p", "", "Project name")
+	flagSet.StringVar(&output, "output", "${project}/results.json", "Output file")
+	flagSet.StringVar(&literal, "literal", "", "Literal value")
+	flagSet.StringSliceVar(&paths, "paths", nil, "Paths")
+
+	err := flag.CommandLine.Parse([]string{"-p", "${GOFLAGS_TEST_VALUE}", "-literal", "$${project}", "-paths", "${output},${p}/logs"})
+	require.Nil(t, err, "could not parse flags")
+
+	err = flagSet.interpolateValues()
+	require.Nil(t, err, "could not interpolate values")
+	require.Equal(t, "value", project, "could not expand env value")
+	require.Equal(t, "value/results.json", output, "could not interpolate default value")
+	require.Equal(t, "${project}", literal, "could not keep escaped reference")
+	require.Equal(t, StringSlice{"value/results.json", "value/logs"}, paths, "could not interpolate slice value")
+
+	tearDown(t.Name())
+}
+
+func TestInterpolateValuesCycle(t *testing.T) {
+	flagSet := NewFlagSet()
+	flagSet.Interpolate = true
+
+	var first, second string
+	flagSet.StringVar(&first, "first", "${second}", "First value")
+	flagSet.StringVar(&second, "second", "a/${first}", "Second value")
+
+	err := flagSet.interpolateValues()
+	require.NotNil(t, err, "could interpolate cyclic values")
+	require.Contains(t, err.Error(), "first -> second -> first")
+
+	tearDown(t.Name())
+}
+
+func TestExpandConfigEnv(t *testing.T) {
+	os.Setenv("GOFLAGS_TEST_VALUE", "value")
+	defer os.Unsetenv("GOFLAGS_TEST_VALUE")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.ExpandConfigEnv = true
+
+	var cli, config string
+	var slice StringSlice
+	flagSet.StringVar(&cli, "cli", "", "Cli value")
+	flagSet.StringVar(&config, "config-value", "", "Config value")
+	flagSet.StringSliceVar(&slice, "slice", nil, "Slice value")
+
+	err := flag.CommandLine.Parse([]string{"-cli", "${GOFLAGS_TEST_VALUE}"})
+	require.Nil(t, err, "could not parse flags")
+
+	err = ioutil.WriteFile("test.yaml", []byte("config-value: ${GOFLAGS_TEST_VALUE}/$${HOME}\nslice:\n - ${GOFLAGS_TEST_VALUE}\n - b"), os.ModePerm)
+	require.Nil(t, err, "could not write temporary config")
+	defer os.Remove("test.yaml")
+
+	err = flagSet.MergeConfigFile("test.yaml")
+	require.Nil(t, err, "could not merge temporary config")
+
+	require.Equal(t, "${GOFLAGS_TEST_VALUE}", cli, "could expand cli value")
+	require.Equal(t, "value/${HOME}", config, "could not expand config value")
+	require.Equal(t, StringSlice{"value", "b"}, slice, "could not expand config slice value")
+
+	tearDown(t.Name())
+}
@@ -0,0 +1,114 @@
+package goflags
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// lookupValue returns the typed value of a flag by its short or long name
+func (flagSet *FlagSet) lookupValue(name string) (interface{}, error) {
+	currentFlag := flagSet.CommandLine().Lookup(name)
+	if currentFlag == nil {
+		return nil, errors.Errorf("flag -%s is not defined", name)
+	}
+	flagSet.valuesMutex.RLock()
+	defer flagSet.valuesMutex.RUnlock()
+	return typedValue(unwrapValue(currentFlag.Value)), nil
+}
+
+// typeError returns the error of a flag read as the wrong type
+func typeError(name, expected string, value interface{}) error {
+	return errors.Errorf("flag -%s is not of type %s, its value is a %T", name, expected, value)
+}
+
+// GetString returns the value of a string, enum or password flag
+func (flagSet *FlagSet) GetString(name string) (string, error) {
+	value, err := flagSet.lookupValue(name)
+	if err != nil {
+		return "", err
+	}
+	typed, ok := value.(string)
+	if !ok {
+		return "", typeError(name, "string", value)
+	}
+	return typed, nil
+}
+
+// GetBool returns the value of a bool flag
+func (flagSet *FlagSet) GetBool(name string) (bool, error) {
+	value, err := flagSet.lookupValue(name)
+	if err != nil {
+		return false, err
+	}
+	typed, ok := value.(bool)
+	if !ok {
+		return false, typeError(name, "bool", value)
+	}
+	return typed, nil
+}
+
+// GetInt returns the value of an int flag
+func (flagSet *FlagSet) GetInt(name string) (int, error) {
+	value, err := flagSet.lookupValue(name)
+	if err != nil {
+		return 0, err
+	}
+	typed, ok := value.(int)
+	if !ok {
+		return 0, typeError(name, "int", value)
+	}
+	return typed, nil
+}
+
+// GetFloat returns the value of a float flag
+func (flagSet *FlagSet) GetFloat(name string) (float64, error) {
+	value, err := flagSet.lookupValue(name)
+	if err != nil {
+		return 0, err
+	}
+	typed, ok := value.(float64)
+	if !ok {
+		return 0, typeError(name, "float", value)
+	}
+	return typed, nil
+}
+
+// GetDuration returns the value of a duration flag
+func (flagSet *FlagSet) GetDuration(name string) (time.Duration, error) {
+	value, err := flagSet.lookupValue(name)
+	if err != nil {
+		return 0, err
+	}
+	typed, ok := value.(time.Duration)
+	if !ok {
+		return 0, typeError(name, "duration", value)
+	}
+	return typed, nil
+}
+
+// GetStringSlice returns the value of a string slice, enum slice or multi choice flag
+func (flagSet *FlagSet) GetStringSlice(name string) ([]string, error) {
+	value, err := flagSet.lookupValue(name)
+	if err != nil {
+		return nil, err
+	}
+	typed, ok := value.([]string)
+	if !ok {
+		return nil, typeError(name, "string slice", value)
+	}
+	return append([]string(nil), typed...), nil
+}
+
+// GetBytes returns the value of a hex bytes flag
+func (flagSet *FlagSet) GetBytes(name string) ([]byte, error) {
+	value, err := flagSet.lookupValue(name)
+	if err != nil {
+		return nil, err
+	}
+	typed, ok := value.([]byte)
+	if !ok {
+		return nil, typeError(name, "bytes", value)
+	}
+	return append([]byte(nil), typed...), nil
+}
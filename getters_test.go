@@ -0,0 +1,62 @@
+package goflags
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetters(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var output, format string
+	var verbose bool
+	var threads int
+	var rate float64
+	var timeout time.Duration
+	var targets StringSlice
+	var key []byte
+	flagSet.StringVarP(&output, "output", "o", "", "Output file")
+	flagSet.EnumVar(&format, "format", "json", []string{"json", "yaml"}, "Output format")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flagSet.IntVarP(&threads, "threads", "t", 10, "Threads")
+	flagSet.FloatVar(&rate, "rate", 1.5, "Rate")
+	flagSet.DurationVar(&timeout, "timeout", time.Second, "Timeout")
+	flagSet.StringSliceVar(&targets, "targets", nil, "Targets")
+	flagSet.HexBytesVar(&key, "key", nil, 0, 0, "Key")
+
+	err := flagSet.ParseArgs([]string{"-o", "out.txt", "-format", "yaml", "-verbose", "-t", "20", "-targets", "a.com,b.com", "-key", "cafe"})
+	require.Nil(t, err, "could not parse flags")
+
+	stringValue, err := flagSet.GetString("output")
+	require.Nil(t, err)
+	require.Equal(t, "out.txt", stringValue)
+	stringValue, err = flagSet.GetString("format")
+	require.Nil(t, err)
+	require.Equal(t, "yaml", stringValue)
+	boolValue, err := flagSet.GetBool("verbose")
+	require.Nil(t, err)
+	require.True(t, boolValue)
+	intValue, err := flagSet.GetInt("t")
+	require.Nil(t, err)
+	require.Equal(t, 20, intValue)
+	floatValue, err := flagSet.GetFloat("rate")
+	require.Nil(t, err)
+	require.Equal(t, 1.5, floatValue)
+	durationValue, err := flagSet.GetDuration("timeout")
+	require.Nil(t, err)
+	require.Equal(t, time.Second, durationValue)
+	sliceValue, err := flagSet.GetStringSlice("targets")
+	require.Nil(t, err)
+	require.Equal(t, []string{"a.com", "b.com"}, sliceValue)
+	bytesValue, err := flagSet.GetBytes("key")
+	require.Nil(t, err)
+	require.Equal(t, []byte{0xca, 0xfe}, bytesValue)
+
+	_, err = flagSet.GetInt("output")
+	require.EqualError(t, err, "flag -output is not of type int, its value is a string")
+	_, err = flagSet.GetString("missing")
+	require.EqualError(t, err, "flag -missing is not defined")
+}
@@ -3,8 +3,14 @@ module github.com/projectdiscovery/goflags
 go 1.14
 
 require (
+	filippo.io/age v1.0.0
 	github.com/cnf/structhash v0.0.0-20201127153200-e1b16c1ebc08
+	github.com/hashicorp/hcl v1.0.0
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.7.0
+	github.com/zalando/go-keyring v0.2.1
+	golang.org/x/sys v0.0.0-20210903071746-97244b99971b
+	golang.org/x/term v0.0.0-20210615171337-6886f2dfbf5b
 	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
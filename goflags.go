@@ -4,14 +4,19 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
+	"text/template"
+	"time"
 
 	"github.com/cnf/structhash"
 	"github.com/pkg/errors"
@@ -20,83 +25,446 @@ import (
 
 // FlagSet is a list of flags for an application
 type FlagSet struct {
-	Marshal     bool
-	description string
-	flagKeys    InsertionOrderedMap
+	Marshal          bool
+	ExpandEnv        bool // expands ${NAME} environment variable references in flag and config values
+	Interpolate      bool // resolves ${name} references to other flags in flag values after parsing
+	SingleOccurrence bool // makes providing a non-slice flag more than once a parse error
+	ExpandConfigEnv  bool // expands ${NAME} environment variable references in config file values only
+	BackupConfig     bool // keeps a .bak copy of config files overwritten by WriteConfig
+	UsageConfigKeys  bool // shows the config file key of each flag in the usage
+	DotEnvOverride   bool // makes values of loaded .env files take precedence over the environment
+	StrictConfig     bool // makes config keys not matching any flag an error
+	LibraryMode      bool // makes Parse return registration errors instead of panicking
+	CaseInsensitive  bool // matches flag names on the command line and config keys ignoring their case
+	DoubleDashUsage  bool // shows long flag names as --name in the usage, as they can be provided
+	Interspersed     bool // parses the flags provided after positional arguments too
+	FreezeAfterParse bool // makes registering flags or setting their values after Parse an error
+	// CombinedShortFlags makes Parse split clusters of single letter flags like -vqf,
+	// unless a flag is defined with the name of the whole cluster.
+	CombinedShortFlags bool
+	// IgnoreUnknownFlags makes Parse skip the flags which are not defined, which
+	// are then returned by UnknownFlags, instead of failing on them.
+	IgnoreUnknownFlags bool
+	// CompactHelp makes -h show only the first line of the usage of each flag,
+	// the full usage being shown by -help and the hidden flags by -help-all.
+	CompactHelp bool
+	// ColorUsage colors the flag names, types and default values of the usage,
+	// unless the output is plain or NO_COLOR is set.
+	ColorUsage bool
+	// PagerUsage pipes the usage through $PAGER when it is written to a terminal
+	// it doesn't fit in, writing it directly if the pager can't be started.
+	PagerUsage bool
+	// PlainOutput writes the usage without colors, wrapping or pager even on
+	// a terminal, as done when the output is not a terminal or TERM is dumb.
+	PlainOutput bool
+
+	description          string
+	applicationName      string
+	helpHeader           string
+	helpFooter           string
+	usagePattern         string
+	flagKeys             InsertionOrderedMap
+	stdin                io.Reader
+	configFilePath       string
+	customConfigFilePath bool
+	autoConfigDisabled   bool
+	explicitConfigFile   string
+	writeConfigFile      string
+	configSearchPaths    []string
+	dotEnvFiles          []string
+	profile              string
+	profileFound         bool
+	configVersion        int
+	configMigrations     map[int]ConfigMigration
+	configFileMode       os.FileMode
+	flagSources          map[string]Source
+	valuesMutex          *sync.RWMutex
+	configKey            string
+	keyringEnabled       bool
+	valueResolvers       map[string]ValueResolver
+	valueSources         []prioritizedSource
+	requiredTogether     [][]string
+	oneRequired          [][]string
+	requirements         []conditionalRequirement
+	validationFunc       func(flagSet *FlagSet) error
+	commandLine          *flag.FlagSet
+	registrationErrs     Errors
+	errorHandling        *flag.ErrorHandling
+	exitCode             int
+	unknownFlags         []string
+	remainder            []string
+	positionals          []*positional
+	parent               *FlagSet
+	commandName          string
+	commands             []*FlagSet
+	invokedCommand       *FlagSet
+	inherited            map[string]*FlagSet
+	aliases              []string
+	defaultCommand       string
+	configSections       []configSection
+	parseErrorHandling   *flag.ErrorHandling
+	onUsageError         func(arg string, err error)
+	frozen               bool
+	groups               []flagGroup
+	examples             []example
+	usageTemplate        *template.Template
+	requestedHelpFormat  string
+	usageMode            usageMode
+	version              versionInfo
+	showVersion          bool
+	usageOrder           UsageOrder
+	usageComparator      func(a, b *FlagInfo) bool
 }
 
-type flagData struct {
-	usage        string
-	short        string
-	long         string
-	defaultValue interface{}
+// FlagData is the metadata of a single registered flag
+type FlagData struct {
+	usage            string
+	short            string
+	long             string
+	defaultValue     interface{}
+	stdin            bool
+	singleOccurrence bool
+	enum             *enumOptions
+	password         bool
+	sensitive        bool
+	skipConfig       bool
+	envNames         []string
+	configOnly       bool
+	required         bool
+	validators       []func(value interface{}) error
+	min, max         interface{}
+	group            string
+	hidden           bool
+	deprecated       string
+	replacedBy       string
+	hideDefault      bool
+	defaultText      string
+	longDescription  string
+	related          []string
+	completeFiles    bool
+	completer        func(toComplete string) []string
+	warned           bool
+	flagSet          *FlagSet        `hash:"-"`
+	registered       registeredValue `hash:"-"`
 }
 
 // NewFlagSet creates a new flagSet structure for the application
+//
+// The flags are registered on the global flag.CommandLine, use NewScopedFlagSet
+// to get a FlagSet independent of the global flag state.
 func NewFlagSet() *FlagSet {
-	return &FlagSet{flagKeys: *newInsertionOrderedMap()}
+	return &FlagSet{flagKeys: *newInsertionOrderedMap(), stdin: os.Stdin, configFileMode: defaultConfigFileMode, valuesMutex: &sync.RWMutex{}}
+}
+
+// NewScopedFlagSet creates a new flagSet owning its flag.FlagSet, so that several
+// of them can be used in the same process without affecting the global flag state.
+func NewScopedFlagSet(name string, errorHandling flag.ErrorHandling) *FlagSet {
+	flagSet := NewFlagSet()
+	flagSet.commandLine = flag.NewFlagSet(name, errorHandling)
+	return flagSet
+}
+
+// CommandLine returns the flag.FlagSet the flags are registered on
+func (flagSet *FlagSet) CommandLine() *flag.FlagSet {
+	if flagSet.commandLine != nil {
+		return flagSet.commandLine
+	}
+	return flag.CommandLine
 }
 
 func newInsertionOrderedMap() *InsertionOrderedMap {
 	return &InsertionOrderedMap{
-		values: make(map[string]*flagData),
+		values: make(map[string]*FlagData),
 		keys:   make([]string, 0, 0),
 	}
 }
 
 // Hash returns the unique hash for a flagData structure
 // NOTE: Hash panics when the structure cannot be hashed.
-func (flagSet *flagData) Hash() string {
+func (flagSet *FlagData) Hash() string {
 	hash, _ := structhash.Hash(flagSet, 1)
 	return hash
 }
 
+// name returns the name the flag was registered with, preferring the longname
+func (flagData *FlagData) name() string {
+	if flagData.long != "" {
+		return flagData.long
+	}
+	return flagData.short
+}
+
+// Hidden keeps the flag working while omitting it from the usage
+// and from the generated default config file.
+func (flagData *FlagData) Hidden() *FlagData {
+	flagData.hidden = true
+	return flagData
+}
+
+// HideDefault omits the default value of the flag from the usage
+func (flagData *FlagData) HideDefault() *FlagData {
+	flagData.hideDefault = true
+	return flagData
+}
+
+// DefaultText sets the text shown as the default value of the flag in the
+// usage instead of the value itself, like "all CPUs".
+func (flagData *FlagData) DefaultText(text string) *FlagData {
+	flagData.defaultText = text
+	return flagData
+}
+
+// LongDescription sets an extended description of the flag, which can span
+// several paragraphs, shown below its usage by -help-all and in the markdown
+// reference, keeping the usage itself to one line.
+func (flagData *FlagData) LongDescription(text string) *FlagData {
+	flagData.longDescription = text
+	return flagData
+}
+
 // SetDescription sets the description field for a flagSet to a value.
 func (flagSet *FlagSet) SetDescription(description string) {
 	flagSet.description = description
 }
 
-// MergeConfigFile reads a config file to merge values from.
+// SetHelpHeader sets a text shown at the beginning of the usage, like a banner
+func (flagSet *FlagSet) SetHelpHeader(header string) {
+	flagSet.helpHeader = header
+}
+
+// SetHelpFooter sets a text shown at the end of the usage, like links
+// to the documentation and to report bugs.
+func (flagSet *FlagSet) SetHelpFooter(footer string) {
+	flagSet.helpFooter = footer
+}
+
+// SetAppName sets the name of the application shown in the usage and version,
+// and naming its config and cache directories and keyring entries, instead
+// of the name of the binary, which changes under symlinks and go test.
+func (flagSet *FlagSet) SetAppName(name string) {
+	flagSet.applicationName = name
+}
+
+// SetUsagePattern sets the usage line shown in the usage, like
+// "tool [flags] <target>...", instead of the one built from the flags,
+// commands and positional arguments.
+func (flagSet *FlagSet) SetUsagePattern(pattern string) {
+	flagSet.usagePattern = pattern
+}
+
+// SetConfigFilePath sets the path of the config file created and merged by Parse,
+// replacing the default ~/.config/<app>/config.yaml one. An empty path disables
+// the config file handling of Parse entirely, like DisableAutoConfig.
+//
+// Invalid values of this file are ignored, use SetConfigSearchPaths for config
+// files whose errors are reported by Parse.
+func (flagSet *FlagSet) SetConfigFilePath(path string) {
+	flagSet.configFilePath = path
+	flagSet.customConfigFilePath = true
+}
+
+// DisableAutoConfig stops Parse from creating and merging the default config file,
+// so that parsing the flags has no filesystem side effects.
+func (flagSet *FlagSet) DisableAutoConfig() {
+	flagSet.autoConfigDisabled = true
+}
+
+// MergeConfigFile reads a config file to merge values from. The file can also be
+// an HTTP(S) URL or a location in a config source registered by its URL scheme.
 func (flagSet *FlagSet) MergeConfigFile(file string) error {
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+		return flagSet.MergeConfigURL(file, nil)
+	}
+	if location, source, ok := configSourceLocation(file); ok {
+		return flagSet.mergeConfigSource(file, location, source)
+	}
 	return flagSet.readConfigFile(file)
 }
 
-// Parse parses the flags provided to the library.
+// Parse parses the flags provided to the library from the command line arguments.
 func (flagSet *FlagSet) Parse() error {
-	flag.CommandLine.Usage = flagSet.usageFunc
-	flag.Parse()
+	return flagSet.ParseArgs(os.Args[1:])
+}
 
-	appName := filepath.Base(os.Args[0])
-	// trim extension from app name
-	appName = strings.TrimSuffix(appName, filepath.Ext(appName))
-	homePath, err := os.UserHomeDir()
-	if err != nil {
+// ParseArgs parses the flags from the arguments, which must not include the
+// program name. Problems found in the values of the flags are all reported
+// together, as Errors.
+func (flagSet *FlagSet) ParseArgs(args []string) error {
+	flagSet.frozen = false
+	defer func() { flagSet.frozen = flagSet.FreezeAfterParse }()
+
+	flagSet.registerConfigFlags()
+	flagSet.registerProfileFlag()
+	flagSet.registerVersionFlag()
+	if err := flagSet.registrationErrs.err(); err != nil {
+		return flagSet.handleError(err, false)
+	}
+	if len(args) > 0 && args[0] == completeCommandName && flagSet.parent == nil {
+		flagSet.printCompletions(args[1:])
+		return flagSet.handleError(ErrCompletion, true)
+	}
+
+	var errs Errors
+	errs.add(flagSet.applyEnv())
+	flagSet.CommandLine().Usage = flagSet.usageFunc
+	if flagSet.parseErrorHandling == nil {
+		errorHandling := flagSet.CommandLine().ErrorHandling()
+		flagSet.parseErrorHandling = &errorHandling
+	}
+	flagSet.CommandLine().Init(flagSet.CommandLine().Name(), flag.ContinueOnError)
+	for _, command := range flagSet.commands {
+		command.configSections = nil
+	}
+	if flagSet.defaultCommand != "" {
+		args = flagSet.withDefaultCommand(args)
+	}
+	if flagSet.CaseInsensitive {
+		args = flagSet.foldFlagNames(args)
+	}
+	if flagSet.CombinedShortFlags {
+		args = flagSet.expandShortFlags(args)
+	}
+	if flagSet.Interspersed && len(flagSet.commands) == 0 {
+		args = flagSet.intersperse(args)
+	}
+	if flagSet.IgnoreUnknownFlags {
+		args, flagSet.unknownFlags = flagSet.filterUnknownFlags(args)
+	}
+	help, helpRequested := flagSet.requestedHelp(args)
+	flagSet.requestedHelpFormat = help.value
+	flagSet.usageMode = flagSet.usageModeOf(help)
+	if helpRequested && help.name == helpAllFlagName {
+		flagSet.usageFunc()
+		return flagSet.handleError(flag.ErrHelp, true)
+	}
+	if err := flagSet.CommandLine().Parse(args); err != nil {
+		flagSet.reportUsageError(err)
+		return flagSet.handleError(err, true)
+	}
+	if flagSet.showVersion {
+		flagSet.printVersion()
+		return flagSet.handleError(ErrVersion, true)
+	}
+	flagSet.remainder = flagSet.findRemainder(args)
+	errs.add(flagSet.bindPositionals())
+	errs.add(flagSet.checkConfigOnlyFlags())
+	flagSet.recordCLISources()
+
+	errs.add(flagSet.mergeParseConfigFiles())
+	errs.add(flagSet.checkProfile())
+	errs.add(flagSet.readKeyringValues())
+	errs.add(flagSet.applyValueSources())
+	errs.add(flagSet.handleDeprecated())
+	errs.add(flagSet.resolveValues())
+	if flagSet.Interpolate {
+		errs.add(flagSet.interpolateValues())
+	}
+	// stdin is only consumed and passwords prompted for once the other sources are valid
+	if len(errs) == 0 {
+		errs.add(flagSet.readStdinValues())
+	}
+	if len(errs) == 0 {
+		errs.add(flagSet.promptPasswords())
+	}
+	errs.add(flagSet.checkRequired())
+	errs.add(flagSet.checkRequiredTogether())
+	errs.add(flagSet.checkOneRequired())
+	errs.add(flagSet.checkConditionalRequirements())
+	errs.add(flagSet.runValidators())
+	errs.add(flagSet.validateChoices())
+	if flagSet.validationFunc != nil {
+		errs.add(flagSet.validationFunc(flagSet))
+	}
+	if len(errs) > 0 {
+		flagSet.reportUsageError(errs)
+		return flagSet.handleError(errs, false)
+	}
+	errs.add(flagSet.dispatchCommand()) // reported by the command
+	if len(errs) > 0 {
+		return flagSet.handleError(errs, false)
+	}
+	if flagSet.writeConfigFile != "" {
+		return flagSet.handleError(flagSet.WriteConfig(flagSet.writeConfigFile), false)
+	}
+	return nil
+}
+
+// mergeDefaultConfig merges the default config file of the application,
+// creating it from the registered flags if it does not exist yet.
+//
+// Unlike the explicit config files, the errors of the default config file are
+// ignored, as it is implicitly read on every run.
+func (flagSet *FlagSet) mergeDefaultConfig() error {
+	if flagSet.autoConfigDisabled {
+		return nil
+	}
+	config, err := flagSet.configFile()
+	if err != nil || config == "" {
 		return err
 	}
 
-	config := filepath.Join(homePath, ".config", appName, "config.yaml")
-	_ = os.MkdirAll(filepath.Dir(config), os.ModePerm)
+	_ = os.MkdirAll(filepath.Dir(config), flagSet.configDirMode())
 	if _, err := os.Stat(config); os.IsNotExist(err) {
 		configData := flagSet.generateDefaultConfig()
-		return ioutil.WriteFile(config, configData, os.ModePerm)
+		return flagSet.saveConfigFile(config, configData, false)
 	}
-	flagSet.MergeConfigFile(config) // try to read default config after parsing flags
+	_ = flagSet.MergeConfigFile(config) // try to read default config after parsing flags
 	return nil
 }
 
+// configFile returns the path of the default config file of the application
+func (flagSet *FlagSet) configFile() (string, error) {
+	if flagSet.customConfigFilePath {
+		return flagSet.configFilePath, nil
+	}
+
+	homePath, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homePath, ".config", flagSet.appName(), "config.yaml"), nil
+}
+
+// appName returns the name of the application set with SetAppName,
+// or the name of the application binary without its extension.
+func (flagSet *FlagSet) appName() string {
+	if flagSet.parent != nil {
+		return flagSet.parent.appName()
+	}
+	if flagSet.applicationName != "" {
+		return flagSet.applicationName
+	}
+	name := filepath.Base(os.Args[0])
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
 // generateDefaultConfig generates a default YAML config file for a flagset.
 func (flagSet *FlagSet) generateDefaultConfig() []byte {
 	hashes := make(map[string]struct{})
 	configBuffer := &bytes.Buffer{}
 	configBuffer.WriteString("# ")
-	configBuffer.WriteString(path.Base(os.Args[0]))
+	if flagSet.applicationName != "" {
+		configBuffer.WriteString(flagSet.applicationName)
+	} else {
+		configBuffer.WriteString(path.Base(os.Args[0]))
+	}
 	configBuffer.WriteString(" config file\n# generated by https://github.com/projectdiscovery/goflags\n\n")
+	if flagSet.configVersion > 0 {
+		configBuffer.WriteString(configVersionKey + ": " + strconv.Itoa(flagSet.configVersion) + "\n\n")
+	}
 
 	// Attempts to marshal natively if proper flag is set, in case of errors fallback to normal mechanism
 	if flagSet.Marshal {
 		flagsToMarshall := make(map[string]interface{})
 
-		flagSet.flagKeys.forEach(func(key string, data *flagData) {
+		flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+			if data.password || data.skipConfig || data.hidden {
+				return
+			}
+			if data.sensitive {
+				flagsToMarshall[key] = ""
+				return
+			}
 			flagsToMarshall[key] = data.defaultValue
 		})
 
@@ -107,9 +475,9 @@ func (flagSet *FlagSet) generateDefaultConfig() []byte {
 		}
 	}
 
-	flagSet.flagKeys.forEach(func(key string, data *flagData) {
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
 		dataHash := data.Hash()
-		if _, ok := hashes[dataHash]; ok {
+		if _, ok := hashes[dataHash]; ok || data.password || data.skipConfig || data.hidden {
 			return
 		}
 		hashes[dataHash] = struct{}{}
@@ -120,10 +488,12 @@ func (flagSet *FlagSet) generateDefaultConfig() []byte {
 		configBuffer.WriteString("#")
 		configBuffer.WriteString(data.long)
 		configBuffer.WriteString(": ")
-		if s, ok := data.defaultValue.(string); ok {
-			configBuffer.WriteString(s)
-		} else if dv, ok := data.defaultValue.(flag.Value); ok {
-			configBuffer.WriteString(dv.String())
+		if !data.sensitive { // sensitive values are never written to the config file
+			if s, ok := data.defaultValue.(string); ok {
+				configBuffer.WriteString(s)
+			} else if dv, ok := data.defaultValue.(flag.Value); ok {
+				configBuffer.WriteString(dv.String())
+			}
 		}
 
 		configBuffer.WriteString("\n\n")
@@ -137,202 +507,307 @@ func (flagSet *FlagSet) generateDefaultConfig() []byte {
 //
 // Command line flags however always take precedence over config file ones.
 func (flagSet *FlagSet) readConfigFile(filePath string) error {
-	file, err := os.Open(filePath)
+	content, err := ioutil.ReadFile(filePath)
 	if err != nil {
 		return errors.Wrap(err, "could not open config file")
 	}
-	defer file.Close()
+	return flagSet.mergeConfigContent(content, filePath, filepath.Ext(filePath), true)
+}
 
-	data := make(map[string]interface{})
-	err = yaml.NewDecoder(file).Decode(&data)
+// mergeConfigContent decodes the content of a config file read from source
+// and merges it into the flags.
+//
+// When rewritable is set, YAML config files are rewritten in place after being
+// upgraded to the current config version, keeping a backup of the original.
+// Encrypted config files are never rewritten, to not write their secrets in plaintext.
+func (flagSet *FlagSet) mergeConfigContent(content []byte, source, extension string, rewritable bool) error {
+	if isEncryptedConfig(content) {
+		decrypted, err := flagSet.decryptConfig(content)
+		if err != nil {
+			return errors.Wrapf(err, "could not decrypt config file %s", source)
+		}
+		content, rewritable = decrypted, false
+	}
+	if _, ok := configDecoders[strings.ToLower(extension)]; !ok {
+		decrypted, sops, err := flagSet.decryptSopsConfig(content)
+		if err != nil {
+			return errors.Wrapf(err, "could not decrypt sops config file %s", source)
+		}
+		if sops {
+			content, rewritable = decrypted, false
+		}
+	}
+	data, lines, err := decodeConfig(content, extension)
 	if err != nil {
 		return errors.Wrap(err, "could not unmarshal config file")
 	}
-	flag.CommandLine.VisitAll(func(fl *flag.Flag) {
+	encrypted, err := flagSet.decryptConfigValues(data)
+	if err != nil {
+		return errors.Wrapf(err, "could not decrypt config file %s", source)
+	}
+	rewritable = rewritable && !encrypted
+	migrated, err := flagSet.migrateConfig(source, data)
+	if err != nil {
+		return err
+	}
+	if _, ok := configDecoders[strings.ToLower(extension)]; migrated && rewritable && !ok {
+		if err := flagSet.rewriteConfigFile(source, data); err != nil {
+			return err
+		}
+	}
+	return flagSet.mergeConfigData(data, source, lines)
+}
+
+// mergeConfigData sets the flags found in the decoded config data
+// which have not been set by the command line.
+//
+// Values not matching the type of their flag are reported together,
+// with the line numbers of their keys when they are known.
+func (flagSet *FlagSet) mergeConfigData(data map[string]interface{}, source string, lines map[string]int) error {
+	if flagSet.CaseInsensitive {
+		data, lines = flagSet.foldConfigKeys(data, lines)
+	}
+	var errs Errors
+	data, lines, err := flagSet.flattenGroupSections(data, source, lines)
+	errs.add(err)
+	data, profileData := flagSet.applyProfile(data)
+	flagSet.recordConfigSections(data, source, lines)
+
+	flagSet.CommandLine().VisitAll(func(fl *flag.Flag) {
+		if flagData, ok := flagSet.flagKeys.values[fl.Name]; ok && flagData.skipConfig {
+			return
+		}
 		item, ok := data[fl.Name]
-		value := fl.Value.String()
-
-		if strings.EqualFold(fl.DefValue, value) && ok {
-			switch data := item.(type) {
-			case string:
-				_ = fl.Value.Set(data)
-			case bool:
-				_ = fl.Value.Set(strconv.FormatBool(data))
-			case int:
-				_ = fl.Value.Set(strconv.Itoa(data))
-			case []interface{}:
-				for _, v := range data {
-					vStr, ok := v.(string)
-					if ok {
-						_ = fl.Value.Set(vStr)
-					}
+		if ok && !flagSet.Changed(fl.Name) {
+			if err := flagSet.setConfigValue(fl.Value, item); err == nil {
+				flagSet.setSource(fl.Name, Source{Kind: SourceConfigFile, Name: source})
+			} else {
+				key := fl.Name
+				if _, ok := profileData[key]; ok {
+					key = profilesConfigKey + "." + flagSet.profile + "." + key
 				}
+				errs = append(errs, &ConfigValueError{File: source, Key: key, Line: lines[key], Err: err})
 			}
 		}
 	})
-	return nil
+	if flagSet.StrictConfig {
+		errs.add(flagSet.unknownConfigKeys(data, source, lines))
+	}
+	return errs.err()
 }
 
-// VarP adds a Var flag with a shortname and longname
-func (flagSet *FlagSet) VarP(field flag.Value, long, short, usage string) {
-	flag.Var(field, short, usage)
-	flag.Var(field, long, usage)
+// unknownConfigKeys returns an error for every config key not matching any flag
+func (flagSet *FlagSet) unknownConfigKeys(data map[string]interface{}, source string, lines map[string]int) error {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if key != configVersionKey && key != profilesConfigKey && flagSet.CommandLine().Lookup(key) == nil && flagSet.lookupCommand(key) == nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var errs Errors
+	for _, key := range keys {
+		errs.add(&ConfigValueError{File: source, Key: key, Line: lines[key], Err: errors.New("unknown flag")})
+	}
+	return errs.err()
+}
 
-	flagData := &flagData{
+// VarP adds a Var flag with a shortname and longname. The usage shows the type
+// returned by the Type method of the value if it has one.
+func (flagSet *FlagSet) VarP(field flag.Value, long, short, usage string) *FlagData {
+	flagData := &FlagData{
 		usage:        usage,
 		short:        short,
 		long:         long,
 		defaultValue: field,
 	}
-	flagSet.flagKeys.Set(short, flagData)
-	flagSet.flagKeys.Set(long, flagData)
+	return flagSet.addFlag(field, flagData, short, long)
 }
 
 // Var adds a Var flag with a longname
-func (flagSet *FlagSet) Var(field flag.Value, long, usage string) {
-	flag.Var(field, long, usage)
-
-	flagData := &flagData{
+func (flagSet *FlagSet) Var(field flag.Value, long, usage string) *FlagData {
+	flagData := &FlagData{
 		usage:        usage,
 		long:         long,
 		defaultValue: field,
 	}
-	flagSet.flagKeys.Set(long, flagData)
+	return flagSet.addFlag(field, flagData, long)
 }
 
 // StringVarEnv adds a string flag with a shortname and longname with a default value read from env variable
-// with a default value fallback
-func (flagSet *FlagSet) StringVarEnv(field *string, long, short, defaultValue, envName, usage string) {
+// with a default value fallback. Flags of any type can be bound to env variables with Env.
+func (flagSet *FlagSet) StringVarEnv(field *string, long, short, defaultValue, envName, usage string) *FlagData {
 	if envValue, exists := os.LookupEnv(envName); exists {
 		defaultValue = envValue
 	}
 
-	flagSet.StringVarP(field, long, short, defaultValue, usage)
+	flagData := flagSet.StringVarP(field, long, short, defaultValue, usage)
+	flagData.envNames = []string{envName}
+	return flagData
 }
 
 // StringVarP adds a string flag with a shortname and longname
-func (flagSet *FlagSet) StringVarP(field *string, long, short, defaultValue, usage string) {
-	flag.StringVar(field, short, defaultValue, usage)
-	flag.StringVar(field, long, defaultValue, usage)
+func (flagSet *FlagSet) StringVarP(field *string, long, short, defaultValue, usage string) *FlagData {
+	value := stdlibValue(func(set *flag.FlagSet) { set.StringVar(field, long, defaultValue, usage) })
 
-	flagData := &flagData{
+	flagData := &FlagData{
 		usage:        usage,
 		short:        short,
 		long:         long,
 		defaultValue: defaultValue,
 	}
-	flagSet.flagKeys.Set(short, flagData)
-	flagSet.flagKeys.Set(long, flagData)
+	return flagSet.addFlag(value, flagData, short, long)
 }
 
 // StringVar adds a string flag with a longname
-func (flagSet *FlagSet) StringVar(field *string, long, defaultValue, usage string) {
-	flag.StringVar(field, long, defaultValue, usage)
+func (flagSet *FlagSet) StringVar(field *string, long, defaultValue, usage string) *FlagData {
+	value := stdlibValue(func(set *flag.FlagSet) { set.StringVar(field, long, defaultValue, usage) })
 
-	flagData := &flagData{
+	flagData := &FlagData{
 		usage:        usage,
 		long:         long,
 		defaultValue: defaultValue,
 	}
-	flagSet.flagKeys.Set(long, flagData)
+	return flagSet.addFlag(value, flagData, long)
 }
 
 // BoolVarP adds a bool flag with a shortname and longname
-func (flagSet *FlagSet) BoolVarP(field *bool, long, short string, defaultValue bool, usage string) {
-	flag.BoolVar(field, short, defaultValue, usage)
-	flag.BoolVar(field, long, defaultValue, usage)
+func (flagSet *FlagSet) BoolVarP(field *bool, long, short string, defaultValue bool, usage string) *FlagData {
+	value := stdlibValue(func(set *flag.FlagSet) { set.BoolVar(field, long, defaultValue, usage) })
 
-	flagData := &flagData{
+	flagData := &FlagData{
 		usage:        usage,
 		short:        short,
 		long:         long,
 		defaultValue: strconv.FormatBool(defaultValue),
 	}
-	flagSet.flagKeys.Set(short, flagData)
-	flagSet.flagKeys.Set(long, flagData)
+	return flagSet.addFlag(value, flagData, short, long)
 }
 
 // BoolVar adds a bool flag with a longname
-func (flagSet *FlagSet) BoolVar(field *bool, long string, defaultValue bool, usage string) {
-	flag.BoolVar(field, long, defaultValue, usage)
+func (flagSet *FlagSet) BoolVar(field *bool, long string, defaultValue bool, usage string) *FlagData {
+	value := stdlibValue(func(set *flag.FlagSet) { set.BoolVar(field, long, defaultValue, usage) })
 
-	flagData := &flagData{
+	flagData := &FlagData{
 		usage:        usage,
 		long:         long,
 		defaultValue: strconv.FormatBool(defaultValue),
 	}
-	flagSet.flagKeys.Set(long, flagData)
+	return flagSet.addFlag(value, flagData, long)
 }
 
 // IntVarP adds a int flag with a shortname and longname
-func (flagSet *FlagSet) IntVarP(field *int, long, short string, defaultValue int, usage string) {
-	flag.IntVar(field, short, defaultValue, usage)
-	flag.IntVar(field, long, defaultValue, usage)
+func (flagSet *FlagSet) IntVarP(field *int, long, short string, defaultValue int, usage string) *FlagData {
+	value := stdlibValue(func(set *flag.FlagSet) { set.IntVar(field, long, defaultValue, usage) })
 
-	flagData := &flagData{
+	flagData := &FlagData{
 		usage:        usage,
 		short:        short,
 		long:         long,
 		defaultValue: strconv.Itoa(defaultValue),
 	}
-	flagSet.flagKeys.Set(short, flagData)
-	flagSet.flagKeys.Set(long, flagData)
+	return flagSet.addFlag(value, flagData, short, long)
 }
 
 // IntVar adds a int flag with a longname
-func (flagSet *FlagSet) IntVar(field *int, long string, defaultValue int, usage string) {
-	flag.IntVar(field, long, defaultValue, usage)
+func (flagSet *FlagSet) IntVar(field *int, long string, defaultValue int, usage string) *FlagData {
+	value := stdlibValue(func(set *flag.FlagSet) { set.IntVar(field, long, defaultValue, usage) })
 
-	flagData := &flagData{
+	flagData := &FlagData{
 		usage:        usage,
 		long:         long,
 		defaultValue: strconv.Itoa(defaultValue),
 	}
-	flagSet.flagKeys.Set(long, flagData)
+	return flagSet.addFlag(value, flagData, long)
+}
+
+// FloatVarP adds a float flag with a shortname and longname
+func (flagSet *FlagSet) FloatVarP(field *float64, long, short string, defaultValue float64, usage string) *FlagData {
+	value := stdlibValue(func(set *flag.FlagSet) { set.Float64Var(field, long, defaultValue, usage) })
+
+	flagData := &FlagData{
+		usage:        usage,
+		short:        short,
+		long:         long,
+		defaultValue: strconv.FormatFloat(defaultValue, 'f', -1, 64),
+	}
+	return flagSet.addFlag(value, flagData, short, long)
+}
+
+// FloatVar adds a float flag with a longname
+func (flagSet *FlagSet) FloatVar(field *float64, long string, defaultValue float64, usage string) *FlagData {
+	value := stdlibValue(func(set *flag.FlagSet) { set.Float64Var(field, long, defaultValue, usage) })
+
+	flagData := &FlagData{
+		usage:        usage,
+		long:         long,
+		defaultValue: strconv.FormatFloat(defaultValue, 'f', -1, 64),
+	}
+	return flagSet.addFlag(value, flagData, long)
+}
+
+// DurationVarP adds a duration flag with a shortname and longname
+func (flagSet *FlagSet) DurationVarP(field *time.Duration, long, short string, defaultValue time.Duration, usage string) *FlagData {
+	value := stdlibValue(func(set *flag.FlagSet) { set.DurationVar(field, long, defaultValue, usage) })
+
+	flagData := &FlagData{
+		usage:        usage,
+		short:        short,
+		long:         long,
+		defaultValue: defaultValue.String(),
+	}
+	return flagSet.addFlag(value, flagData, short, long)
+}
+
+// DurationVar adds a duration flag with a longname
+func (flagSet *FlagSet) DurationVar(field *time.Duration, long string, defaultValue time.Duration, usage string) *FlagData {
+	value := stdlibValue(func(set *flag.FlagSet) { set.DurationVar(field, long, defaultValue, usage) })
+
+	flagData := &FlagData{
+		usage:        usage,
+		long:         long,
+		defaultValue: defaultValue.String(),
+	}
+	return flagSet.addFlag(value, flagData, long)
 }
 
 // StringSliceVarP adds a string slice flag with a shortname and longname
-func (flagSet *FlagSet) StringSliceVarP(field *StringSlice, long, short string, defaultValue []string, usage string) {
+func (flagSet *FlagSet) StringSliceVarP(field *StringSlice, long, short string, defaultValue []string, usage string) *FlagData {
 	for _, item := range defaultValue {
 		_ = field.Set(item)
 	}
 
-	flag.Var(field, short, usage)
-	flag.Var(field, long, usage)
-
-	flagData := &flagData{
+	flagData := &FlagData{
 		usage:        usage,
 		short:        short,
 		long:         long,
 		defaultValue: field.createStringArrayDefaultValue(),
 	}
-	flagSet.flagKeys.Set(short, flagData)
-	flagSet.flagKeys.Set(long, flagData)
+	return flagSet.addFlag(field, flagData, short, long)
 }
 
 // StringSliceVar adds a string slice flag with a longname
-func (flagSet *FlagSet) StringSliceVar(field *StringSlice, long string, defaultValue []string, usage string) {
+func (flagSet *FlagSet) StringSliceVar(field *StringSlice, long string, defaultValue []string, usage string) *FlagData {
 	for _, item := range defaultValue {
 		_ = field.Set(item)
 	}
 
-	flag.Var(field, long, usage)
-
-	flagData := &flagData{
+	flagData := &FlagData{
 		usage:        usage,
 		long:         long,
 		defaultValue: field.createStringArrayDefaultValue(),
 	}
-	flagSet.flagKeys.Set(long, flagData)
+	return flagSet.addFlag(field, flagData, long)
 }
 
+// createStringArrayDefaultValue returns the slice as a YAML flow sequence
+// whose items can be read back through Set.
 func (stringSlice *StringSlice) createStringArrayDefaultValue() string {
 	defaultBuilder := &strings.Builder{}
 	defaultBuilder.WriteString("[")
 	for i, k := range *stringSlice {
-		defaultBuilder.WriteString("\"")
-		defaultBuilder.WriteString(k)
-		defaultBuilder.WriteString("\"")
+		defaultBuilder.WriteString(strconv.Quote(quoteSliceItem(k)))
 		if i != len(*stringSlice)-1 {
 			defaultBuilder.WriteString(", ")
 		}
@@ -342,46 +817,188 @@ func (stringSlice *StringSlice) createStringArrayDefaultValue() string {
 }
 
 func (flagSet *FlagSet) usageFunc() {
-	hashes := make(map[string]struct{})
+	output := flagSet.CommandLine().Output()
+	style := flagSet.usageStyle(output)
+	if !flagSet.PagerUsage || flagSet.plainOutput(output) {
+		flagSet.writeUsage(style)
+		return
+	}
+	buffer := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(buffer)
+	flagSet.writeUsage(style)
+	flagSet.CommandLine().SetOutput(output)
+	pageUsage(output, buffer.String())
+}
 
-	cliOutput := flag.CommandLine.Output()
+// writeUsage writes the usage of the FlagSet to its output
+func (flagSet *FlagSet) writeUsage(style usageStyle) {
+	if flagSet.requestedHelpFormat == helpFormatJSON {
+		flagSet.writeUsageJSON()
+		return
+	}
+	if flagSet.usageTemplate != nil {
+		flagSet.executeUsageTemplate()
+		return
+	}
+	cliOutput := flagSet.CommandLine().Output()
+	if flagSet.helpHeader != "" {
+		fmt.Fprintf(cliOutput, "%s\n\n", flagSet.helpHeader)
+	}
+	if flagSet.version.version != "" {
+		flagSet.printVersion()
+	}
 	fmt.Fprintf(cliOutput, "%s\n\n", flagSet.description)
-	fmt.Fprintf(cliOutput, "Usage:\n  %s [flags]\n\n", os.Args[0])
+	fmt.Fprintf(cliOutput, "Usage:\n  %s\n\n", flagSet.usageLine())
+	if len(flagSet.commands) > 0 {
+		fmt.Fprintf(cliOutput, "Commands:\n")
+		writer := tabwriter.NewWriter(cliOutput, 0, 0, 2, ' ', 0)
+		flagSet.writeUsageCommands(writer)
+		writer.Flush()
+		fmt.Fprintln(cliOutput)
+	}
+	if len(flagSet.positionals) > 0 {
+		fmt.Fprintf(cliOutput, "Arguments:\n")
+		writer := tabwriter.NewWriter(cliOutput, 0, 0, 2, ' ', 0)
+		flagSet.writeUsagePositionals(writer)
+		writer.Flush()
+		fmt.Fprintln(cliOutput)
+	}
 	fmt.Fprintf(cliOutput, "Flags:\n")
+	flagSet.writeUsageGroups(cliOutput, func(data *FlagData) bool { return !data.configOnly && (!data.hidden || style.mode == usageAll) }, style)
 
-	writer := tabwriter.NewWriter(cliOutput, 0, 0, 1, ' ', 0)
+	for parent := flagSet.parent; parent != nil; parent = parent.parent {
+		fmt.Fprintf(cliOutput, "\nFlags of %s:\n", parent.commandPath())
+		fmt.Fprint(cliOutput, parent.renderUsageFlags(parent.usageFlags(func(data *FlagData) bool {
+			return !data.configOnly && !data.skipConfig && !data.hidden && flagSet.flagKeys.values[data.name()] == nil
+		}, nil), style))
+	}
+	flagSet.writeUsageExamples(cliOutput)
+	if flagSet.helpFooter != "" {
+		fmt.Fprintf(cliOutput, "\n%s\n", flagSet.helpFooter)
+	}
+}
 
-	flagSet.flagKeys.forEach(func(key string, data *flagData) {
-		currentFlag := flag.CommandLine.Lookup(key)
+// renderUsageFlags returns the aligned usage of the flags
+func (flagSet *FlagSet) renderUsageFlags(flags []*FlagData, style usageStyle) string {
+	buffer := &bytes.Buffer{}
+	writer := tabwriter.NewWriter(buffer, 0, 0, 1, ' ', 0)
+	flagSet.writeUsageFlags(writer, flags, style)
+	writer.Flush()
+	if style.width == 0 {
+		return buffer.String()
+	}
+	return wrapUsage(buffer.String(), style.width)
+}
 
-		dataHash := data.Hash()
-		if _, ok := hashes[dataHash]; ok {
-			return // Don't print the value if printed previously
+// writeUsageFlags writes the usage of the flags
+func (flagSet *FlagSet) writeUsageFlags(writer *tabwriter.Writer, flags []*FlagData, style usageStyle) {
+	for _, data := range flags {
+		currentFlag := *flagSet.CommandLine().Lookup(data.name())
+		currentFlag.Value = unwrapValue(currentFlag.Value)
+
+		if style.mode == usageCompact {
+			compactData := *data
+			compactData.hideDefault, compactData.min, compactData.max = true, nil, nil
+			currentFlag.Usage = strings.SplitN(currentFlag.Usage, "\n", 2)[0]
+			fmt.Fprint(writer, createUsageString(&compactData, &currentFlag, style), "\n")
+			continue
 		}
-		hashes[dataHash] = struct{}{}
 
-		result := createUsageString(data, currentFlag)
+		result := createUsageString(data, &currentFlag, style)
+		if style.width > 0 {
+			result = strings.Replace(result, "\t\t", "\t\t"+wrapMarker, 1)
+		}
+		if data.required {
+			result += " (required)"
+		}
+		if data.deprecated != "" {
+			result += " (deprecated: " + data.deprecated + ")"
+		}
+		result += flagSet.createUsageSources(data)
+		if style.mode == usageAll && data.longDescription != "" {
+			result += createUsageLongDescription(data.longDescription, style)
+		}
 		fmt.Fprint(writer, result, "\n")
+	}
+}
+
+// usageFlags returns the flags selected by the filter, sorted with less or
+// in the order they were registered if less is nil.
+func (flagSet *FlagSet) usageFlags(filter func(data *FlagData) bool, less func(a, b *FlagData) bool) []*FlagData {
+	var flags []*FlagData
+	seen := make(map[*FlagData]struct{})
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if _, ok := seen[data]; ok || !filter(data) {
+			return // the short and long names of a flag are shown on one line
+		}
+		seen[data] = struct{}{}
+		flags = append(flags, data)
 	})
-	writer.Flush()
+	if less != nil {
+		sort.SliceStable(flags, func(i, j int) bool { return less(flags[i], flags[j]) })
+	}
+	return flags
 }
 
 func isNotBlank(value string) bool {
 	return len(strings.TrimSpace(value)) != 0
 }
 
-func createUsageString(data *flagData, currentFlag *flag.Flag) string {
+func createUsageString(data *FlagData, currentFlag *flag.Flag, style usageStyle) string {
 	valueType := reflect.TypeOf(currentFlag.Value)
 
 	result := createUsageFlagNames(data)
-	result += createUsageTypeAndDescription(currentFlag, valueType)
-	result += createUsageDefaultValue(data, currentFlag, valueType)
+	typeAndDescription := createUsageTypeAndDescription(currentFlag, valueType)
+	if bounds := createUsageBounds(data); bounds != "" {
+		typeAndDescription = strings.Replace(typeAndDescription, "\t\t", bounds+"\t\t", 1)
+	}
+	if style.colors {
+		indent := strings.Repeat(" ", 2) + "\t"
+		parts := strings.SplitN(typeAndDescription, "\t\t", 2)
+		result = indent + style.paint(colorName, strings.TrimPrefix(result, indent))
+		typeAndDescription = style.paint(colorType, parts[0]) + "\t\t" + parts[1]
+	}
+	result += typeAndDescription
+	if defaultValue := createUsageDefaultValue(data, currentFlag, valueType); defaultValue != "" {
+		result += " " + style.paint(colorDefault, strings.TrimPrefix(defaultValue, " "))
+	}
 
 	return result
 }
 
-func createUsageDefaultValue(data *flagData, currentFlag *flag.Flag, valueType reflect.Type) string {
+// createUsageLongDescription returns the lines of the long description of
+// a flag, shown below its usage in the description column.
+func createUsageLongDescription(description string, style usageStyle) string {
+	indent := "\n\t\t\t"
+	if style.width > 0 {
+		indent += wrapMarker
+	}
+	return indent + strings.Join(strings.Split(strings.TrimSpace(description), "\n"), indent)
+}
+
+// createUsageSources returns the environment variables and config key the flag can be set with
+func (flagSet *FlagSet) createUsageSources(data *FlagData) string {
+	var result string
+	if len(data.envNames) > 0 {
+		result += " [env: " + strings.Join(data.envNames, ", ") + "]"
+	}
+	if flagSet.UsageConfigKeys && !data.skipConfig && !data.password {
+		result += " [config: " + data.name() + "]"
+	}
+	return result
+}
+
+func createUsageDefaultValue(data *FlagData, currentFlag *flag.Flag, valueType reflect.Type) string {
+	switch {
+	case data.hideDefault:
+		return ""
+	case data.defaultText != "":
+		return " (default " + data.defaultText + ")"
+	}
 	if !isZeroValue(currentFlag, currentFlag.DefValue) {
+		if data.sensitive {
+			return " (default " + redactedValue + ")"
+		}
 		defaultValueTemplate := " (default "
 		switch valueType.String() { // ugly hack because "flag.stringValue" is not exported from the parent library
 		case "*flag.stringValue":
@@ -398,43 +1015,63 @@ func createUsageDefaultValue(data *flagData, currentFlag *flag.Flag, valueType r
 func createUsageTypeAndDescription(currentFlag *flag.Flag, valueType reflect.Type) string {
 	var result string
 
-	flagDisplayType, usage := flag.UnquoteUsage(currentFlag)
+	flagDisplayType, usage := usageTypeName(currentFlag, valueType)
 	if len(flagDisplayType) > 0 {
-		if flagDisplayType == "value" { // hardcoded in the goflags library
-			switch valueType.Kind() {
-			case reflect.Ptr:
-				pointerTypeElement := valueType.Elem()
-				switch pointerTypeElement.Kind() {
-				case reflect.Slice, reflect.Array:
-					switch pointerTypeElement.Elem().Kind() {
-					case reflect.String:
-						flagDisplayType = "string[]"
-					default:
-						flagDisplayType = "value[]"
-					}
-				}
-			}
-		}
 		result += " " + flagDisplayType
 	}
 
 	result += "\t\t"
-	result += strings.ReplaceAll(usage, "\n", "\n"+strings.Repeat(" ", 4)+"\t")
+	result += strings.ReplaceAll(usage, "\n", "\n\t\t\t") // keeps the lines in the description column
 	return result
 }
 
-func createUsageFlagNames(data *flagData) string {
+// namedTypeValue is a flag.Value naming its type in the usage
+type namedTypeValue interface {
+	flag.Value
+	Type() string
+}
+
+// usageTypeName returns the name of the type of the flag value shown in the usage, and the usage.
+// The name is the one quoted in the usage with backquotes, the Type of the value if it has
+// one, or the name derived from the type of the value.
+func usageTypeName(currentFlag *flag.Flag, valueType reflect.Type) (string, string) {
+	flagDisplayType, usage := flag.UnquoteUsage(currentFlag)
+	if flagDisplayType != "value" {
+		return flagDisplayType, usage
+	}
+	if value, ok := currentFlag.Value.(namedTypeValue); ok {
+		return value.Type(), usage
+	}
+	if valueType.Kind() == reflect.Ptr {
+		switch pointerTypeElement := valueType.Elem(); pointerTypeElement.Kind() {
+		case reflect.Slice, reflect.Array:
+			switch pointerTypeElement.Elem().Kind() {
+			case reflect.String:
+				flagDisplayType = "string[]"
+			default:
+				flagDisplayType = "value[]"
+			}
+		}
+	}
+	return flagDisplayType, usage
+}
+
+func createUsageFlagNames(data *FlagData) string {
 	flagNames := strings.Repeat(" ", 2) + "\t"
 
 	var validFlags []string
-	addValidParam := func(value string) {
+	addValidParam := func(prefix, value string) {
 		if isNotBlank(value) {
-			validFlags = append(validFlags, fmt.Sprintf("-%s", value))
+			validFlags = append(validFlags, prefix+value)
 		}
 	}
 
-	addValidParam(data.short)
-	addValidParam(data.long)
+	longPrefix := "-"
+	if data.flagSet != nil && data.flagSet.DoubleDashUsage {
+		longPrefix = "--"
+	}
+	addValidParam("-", data.short)
+	addValidParam(longPrefix, data.long)
 
 	if len(validFlags) == 0 {
 		panic("CLI arguments cannot be empty.")
@@ -1,14 +1,17 @@
 package goflags
 
 import (
+	"bytes"
 	"flag"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -168,3 +171,262 @@ func TestMe(t *testing.T) {
 func tearDown(uniqueValue string) { // sadly there is no official support for setup/teardown/test
 	flag.CommandLine = flag.NewFlagSet(uniqueValue, flag.PanicOnError)
 }
+
+func TestSetConfigFilePath(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	flagSet := NewFlagSet()
+	var data string
+	flagSet.StringVar(&data, "string-value", "default", "String value example")
+
+	configPath := filepath.Join(tempDir, "app", "custom.yaml")
+	flagSet.SetConfigFilePath(configPath)
+	err = flagSet.mergeDefaultConfig()
+	require.Nil(t, err, "could not create config file")
+	require.FileExists(t, configPath, "could not create config at custom path")
+
+	err = ioutil.WriteFile(configPath, []byte("string-value: from-config"), os.ModePerm)
+	require.Nil(t, err, "could not write config file")
+	err = flagSet.mergeDefaultConfig()
+	require.Nil(t, err, "could not merge config file")
+	require.Equal(t, "from-config", data, "could not merge custom config file")
+
+	flagSet.SetConfigFilePath("")
+	configFile, err := flagSet.configFile()
+	require.Nil(t, err)
+	require.Empty(t, configFile, "could not disable config file")
+	require.Nil(t, flagSet.mergeDefaultConfig())
+
+	tearDown(t.Name())
+}
+
+func TestDefaultConfigErrors(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configPath, []byte("threads: ten\nretries: 3\n"), os.ModePerm)
+	require.Nil(t, err, "could not write config file")
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath(configPath)
+	var threads, retries int
+	flagSet.IntVar(&threads, "threads", 10, "Threads")
+	flagSet.IntVar(&retries, "retries", 1, "Retries")
+
+	err = flagSet.ParseArgs(nil)
+	require.Nil(t, err, "could not ignore errors of the default config file")
+	require.Equal(t, 10, threads)
+	require.Equal(t, 3, retries, "could not merge valid values of the default config file")
+
+	flagSet.Reset()
+	err = flagSet.ParseArgs([]string{"-config", configPath})
+	require.NotNil(t, err, "could ignore errors of an explicit config file")
+	require.Equal(t, "threads", err.(Errors)[0].(*ConfigValueError).Key)
+}
+
+func TestDisableAutoConfig(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	flagSet := NewFlagSet()
+	var data string
+	flagSet.StringVar(&data, "string-value", "default", "String value example")
+
+	configPath := filepath.Join(tempDir, "app", "config.yaml")
+	flagSet.SetConfigFilePath(configPath)
+	flagSet.DisableAutoConfig()
+	err = flagSet.mergeDefaultConfig()
+	require.Nil(t, err, "could not skip config file")
+	require.NoDirExists(t, filepath.Dir(configPath), "could create config directory")
+
+	tearDown(t.Name())
+}
+
+func TestScopedFlagSets(t *testing.T) {
+	tearDown(t.Name())
+
+	first := NewScopedFlagSet("first", flag.ContinueOnError)
+	first.SetConfigFilePath("")
+	second := NewScopedFlagSet("second", flag.ContinueOnError)
+	second.SetConfigFilePath("")
+
+	var firstThreads, secondThreads int
+	first.IntVarP(&firstThreads, "threads", "t", 1, "Threads")
+	second.IntVarP(&secondThreads, "threads", "t", 2, "Threads")
+	require.Nil(t, flag.CommandLine.Lookup("threads"), "could register scoped flag globally")
+
+	os.Args = []string{os.Args[0], "-t", "10"}
+	require.Nil(t, first.Parse(), "could not parse first flag set")
+	require.Equal(t, 10, firstThreads)
+	require.Equal(t, 2, secondThreads, "could share values between flag sets")
+	require.Equal(t, "10", first.CommandLine().Lookup("threads").Value.String())
+
+	os.Args = []string{os.Args[0], "-unknown"}
+	require.NotNil(t, second.Parse(), "could parse unknown flag")
+
+	tearDown(t.Name())
+}
+
+func TestParseArgs(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+
+	var threads int
+	var verbose bool
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+
+	os.Args = []string{os.Args[0], "-t", "5"}
+	require.Nil(t, flagSet.ParseArgs([]string{"-t", "10", "-verbose"}), "could not parse arguments")
+	require.Equal(t, 10, threads, "could use process arguments")
+	require.True(t, verbose)
+}
+
+func TestHiddenFlags(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var output string
+	var debugDelay int
+	flagSet.StringVar(&output, "output", "", "Output file")
+	flagSet.IntVar(&debugDelay, "debug-delay", 0, "Delay between requests").Hidden()
+
+	err := flagSet.ParseArgs([]string{"-debug-delay", "5"})
+	require.Nil(t, err, "could not parse hidden flag")
+	require.Equal(t, 5, debugDelay)
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "-output")
+	require.NotContains(t, usage.String(), "debug-delay")
+	require.NotContains(t, string(flagSet.generateDefaultConfig()), "debug-delay")
+}
+
+func TestUsageExamples(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var target string
+	flagSet.StringVarP(&target, "target", "u", "", "Target to scan")
+	flagSet.AddExample("scan a single host", "tool -u https://example.com -json")
+	flagSet.AddExample("scan hosts from stdin", "cat hosts.txt | tool")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.True(t, strings.HasSuffix(usage.String(), "Target to scan\n"+
+		"\n"+
+		"Examples:\n"+
+		"  scan a single host\n"+
+		"    tool -u https://example.com -json\n"+
+		"\n"+
+		"  scan hosts from stdin\n"+
+		"    cat hosts.txt | tool\n"), usage.String())
+}
+
+func TestDefaultValueDisplay(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var resolvers StringSlice
+	var threads, rateLimit int
+	flagSet.StringSliceVar(&resolvers, "resolvers", []string{"1.1.1.1", "8.8.8.8", "9.9.9.9"}, "Resolvers").HideDefault()
+	flagSet.IntVar(&threads, "threads", 0, "Threads").DefaultText("number of CPUs")
+	flagSet.IntVar(&rateLimit, "rate-limit", 150, "Rate limit")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "Resolvers\n")
+	require.Contains(t, usage.String(), "Threads (default number of CPUs)\n")
+	require.Contains(t, usage.String(), "Rate limit (default 150)\n")
+}
+
+type portValue int
+
+func (value *portValue) String() string { return strconv.Itoa(int(*value)) }
+func (value *portValue) Set(input string) error {
+	port, err := strconv.Atoi(input)
+	*value = portValue(port)
+	return err
+}
+func (value *portValue) Type() string { return "port" }
+
+func TestUsageTypeNames(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var timeout time.Duration
+	var mode string
+	var modes []string
+	var key []byte
+	port := portValue(8080)
+	flagSet.DurationVarP(&timeout, "timeout", "t", time.Second, "Timeout")
+	flagSet.EnumVar(&mode, "mode", "fast", []string{"fast", "slow"}, "Mode")
+	flagSet.EnumSliceVar(&modes, "modes", nil, []string{"fast", "slow"}, "Modes")
+	flagSet.HexBytesVar(&key, "key", nil, 0, 0, "Key")
+	flagSet.VarP(&port, "port", "p", "Port")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "Flags:\n"+
+		"   -t, -timeout duration  Timeout (default 1s)\n"+
+		"   -mode string           Mode (default fast)\n"+
+		"   -modes string[]        Modes\n"+
+		"   -key hex               Key\n"+
+		"   -p, -port port         Port (default 8080)\n")
+}
+
+func TestAppNameAndUsagePattern(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.SetAppName("scanner")
+	var target string
+	flagSet.StringVar(&target, "target", "", "Target to scan")
+	scan := flagSet.NewCommand("scan", "Scan the targets")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "Usage:\n  scanner [flags] <command> [command flags]\n")
+	require.Equal(t, "scanner [flags] <command> [command flags]", flagSet.UsageData().Usage)
+	require.Equal(t, "scanner scan", scan.commandPath())
+	require.Equal(t, "scanner", scan.appName())
+	require.Contains(t, string(flagSet.generateDefaultConfig()), "# scanner config file\n")
+
+	flagSet.SetUsagePattern("scanner [flags] <target>...")
+	usage.Reset()
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "Usage:\n  scanner [flags] <target>...\n")
+
+	flagSet.customConfigFilePath = false
+	config, err := flagSet.configFile()
+	require.Nil(t, err, "could not get config file")
+	require.Equal(t, filepath.Join("scanner", "config.yaml"), filepath.Join(filepath.Base(filepath.Dir(config)), filepath.Base(config)))
+}
+
+func TestHelpHeaderAndFooter(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.SetDescription("Fast scanner")
+	flagSet.SetHelpHeader("scanner - by example")
+	flagSet.SetHelpFooter("Docs: https://example.com/docs\nBugs: https://example.com/issues")
+	var target string
+	flagSet.StringVarP(&target, "target", "u", "", "Target to scan")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.True(t, strings.HasPrefix(usage.String(), "scanner - by example\n\nFast scanner\n\n"), usage.String())
+	require.True(t, strings.HasSuffix(usage.String(), "Target to scan\n"+
+		"\n"+
+		"Docs: https://example.com/docs\n"+
+		"Bugs: https://example.com/issues\n"), usage.String())
+
+	data := flagSet.UsageData()
+	require.Equal(t, "scanner - by example", data.Header)
+	require.Equal(t, "Docs: https://example.com/docs\nBugs: https://example.com/issues", data.Footer)
+}
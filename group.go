@@ -0,0 +1,196 @@
+package goflags
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// flagGroup is a titled section of the flags in the usage
+type flagGroup struct {
+	name        string
+	description string
+	sorted      bool
+	hidden      bool
+}
+
+// title returns the heading of the group in the usage
+func (group flagGroup) title() string {
+	if group.description == "" {
+		return strings.ToUpper(group.name)
+	}
+	return strings.ToUpper(group.description)
+}
+
+// SetGroup adds a group of flags shown in its own section of the usage, titled
+// with the description. Groups are shown in the order they are added, after the
+// flags without a group.
+func (flagSet *FlagSet) SetGroup(name, description string) {
+	if group := flagSet.findGroup(name); group != nil {
+		group.description = description
+		return
+	}
+	flagSet.groups = append(flagSet.groups, flagGroup{name: name, description: description})
+}
+
+// CreateGroup adds a group of flags with SetGroup and puts the flags in it
+func (flagSet *FlagSet) CreateGroup(name, description string, flags ...*FlagData) {
+	flagSet.SetGroup(name, description)
+	for _, flagData := range flags {
+		flagData.Group(name)
+	}
+}
+
+// SetGroupOrder sets the order the groups are shown in the usage, the groups
+// which are not named following in the order they were added.
+func (flagSet *FlagSet) SetGroupOrder(names ...string) {
+	groups := make([]flagGroup, 0, len(flagSet.groups))
+	for _, name := range names {
+		if group := flagSet.findGroup(name); group != nil {
+			groups = append(groups, *group)
+		}
+	}
+	for _, group := range flagSet.groups {
+		if !sliceContains(names, group.name) {
+			groups = append(groups, group)
+		}
+	}
+	flagSet.groups = groups
+}
+
+// SortGroup sets whether the flags of the group are shown sorted by name
+// in the usage instead of in the order they were registered.
+func (flagSet *FlagSet) SortGroup(name string, sorted bool) {
+	if group := flagSet.findGroup(name); group != nil {
+		group.sorted = sorted
+	}
+}
+
+// HideGroup hides the group and its flags from the usage, unless the usage of
+// all the flags is requested with -help-all, for advanced options.
+func (flagSet *FlagSet) HideGroup(name string) {
+	if group := flagSet.findGroup(name); group != nil {
+		group.hidden = true
+	}
+}
+
+// Group puts the flag in the group with the name added with SetGroup or
+// CreateGroup. Flags of a group which is not added are shown without a group.
+func (flagData *FlagData) Group(name string) *FlagData {
+	flagData.group = name
+	return flagData
+}
+
+// findGroup returns the group with the name, or nil if it is not added
+func (flagSet *FlagSet) findGroup(name string) *flagGroup {
+	for i := range flagSet.groups {
+		if flagSet.groups[i].name == name {
+			return &flagSet.groups[i]
+		}
+	}
+	return nil
+}
+
+// usageGroup returns the group the flag is shown in, empty for the flags without a group
+func (flagSet *FlagSet) usageGroup(data *FlagData) string {
+	if data.group == "" || flagSet.findGroup(data.group) == nil {
+		return ""
+	}
+	return data.group
+}
+
+// usageSection is a section of the flags in the usage, with an empty group for the flags without a group
+type usageSection struct {
+	group flagGroup
+	flags []*FlagData
+}
+
+// usageSections returns the sections of the flags selected by the filter in the
+// usage order, including the hidden groups if all is true.
+func (flagSet *FlagSet) usageSections(filter func(data *FlagData) bool, all bool) []usageSection {
+	visible := func(data *FlagData) bool {
+		group := flagSet.findGroup(flagSet.usageGroup(data))
+		return filter(data) && (all || group == nil || !group.hidden)
+	}
+	if flagSet.usageOrder == OrderAlphabetical && flagSet.usageComparator == nil {
+		if flags := flagSet.usageFlags(visible, byName); len(flags) > 0 {
+			return []usageSection{{flags: flags}}
+		}
+		return nil
+	}
+
+	var sections []usageSection
+	for _, group := range append([]flagGroup{{}}, flagSet.groups...) {
+		if group.hidden && !all {
+			continue
+		}
+		name := group.name
+		flags := flagSet.usageFlags(func(data *FlagData) bool {
+			return visible(data) && flagSet.usageGroup(data) == name
+		}, flagSet.usageLess(group))
+		if len(flags) > 0 {
+			sections = append(sections, usageSection{group: group, flags: flags})
+		}
+	}
+	return sections
+}
+
+// writeUsageGroups writes the usage of the flags selected by the filter,
+// followed by a section for every group with selected flags.
+func (flagSet *FlagSet) writeUsageGroups(output io.Writer, filter func(data *FlagData) bool, style usageStyle) {
+	for i, section := range flagSet.usageSections(filter, style.mode == usageAll) {
+		if section.group.name != "" {
+			if i > 0 {
+				fmt.Fprintln(output)
+			}
+			fmt.Fprintf(output, "%s:\n", section.group.title())
+		}
+		fmt.Fprint(output, flagSet.renderUsageFlags(section.flags, style))
+	}
+}
+
+// flattenGroupSections returns the config data with the keys of the sections
+// named after a group moved to the top level, as groups only organize the flags
+// of config files, reporting the keys set both in a group section and elsewhere.
+func (flagSet *FlagSet) flattenGroupSections(data map[string]interface{}, source string, lines map[string]int) (map[string]interface{}, map[string]int, error) {
+	var errs Errors
+	flattened := make(map[string]interface{}, len(data))
+	flattenedLines := make(map[string]int, len(lines))
+	sections := make(map[string]string) // section setting each flattened key
+	for key, value := range data {
+		flattened[key] = value
+	}
+	for key, line := range lines {
+		flattenedLines[key] = line
+	}
+	for _, group := range flagSet.groups {
+		section, ok := configMap(data[group.name])
+		if !ok || flagSet.CommandLine().Lookup(group.name) != nil || flagSet.lookupCommand(group.name) != nil {
+			continue
+		}
+		delete(flattened, group.name)
+		keys := make([]string, 0, len(section))
+		for key := range section {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			path := group.name + "." + key
+			if _, ok := flattened[key]; ok {
+				other := key
+				if otherSection, ok := sections[key]; ok {
+					other = otherSection + "." + key
+				}
+				errs.add(&ConfigValueError{File: source, Key: path, Line: lines[path], Err: errors.Errorf("already set by %s", other)})
+				continue
+			}
+			flattened[key] = section[key]
+			flattenedLines[key] = lines[path]
+			sections[key] = group.name
+		}
+	}
+	return flattened, flattenedLines, errs.err()
+}
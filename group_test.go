@@ -0,0 +1,75 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagGroups(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var targets StringSlice
+	var output string
+	var rateLimit int
+	var verbose bool
+	flagSet.SetGroup("input", "Input")
+	flagSet.StringSliceVarP(&targets, "target", "u", nil, "Targets to scan").Group("input")
+	flagSet.CreateGroup("output", "Output",
+		flagSet.StringVarP(&output, "output", "o", "", "Output file"),
+		flagSet.BoolVar(&verbose, "verbose", false, "Verbose output"),
+	)
+	flagSet.IntVar(&rateLimit, "rate-limit", 150, "Requests per second")
+	flagSet.SetGroup("debug", "Debug")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "Flags:\n"+
+		"   -rate-limit int  Requests per second (default 150)\n"+
+		"\n"+
+		"INPUT:\n"+
+		"   -u, -target string[]  Targets to scan\n"+
+		"\n"+
+		"OUTPUT:\n"+
+		"   -o, -output string  Output file\n"+
+		"   -verbose            Verbose output\n")
+	require.NotContains(t, usage.String(), "DEBUG:")
+
+	var groups []string
+	flagSet.VisitAll(func(info *FlagInfo) {
+		groups = append(groups, info.Group)
+	})
+	require.Equal(t, []string{"input", "output", "output", ""}, groups)
+}
+
+func TestGroupOrderAndSorting(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var output, format string
+	var verbose, debug bool
+	flagSet.CreateGroup("output", "Output",
+		flagSet.StringVar(&output, "output", "", "Output file"),
+		flagSet.StringVar(&format, "format", "", "Output format"),
+	)
+	flagSet.CreateGroup("debug", "Debug",
+		flagSet.BoolVar(&verbose, "verbose", false, "Verbose output"),
+		flagSet.BoolVar(&debug, "debug", false, "Debug output"),
+	)
+	flagSet.SetGroupOrder("debug")
+	flagSet.SortGroup("output", true)
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "Flags:\n"+
+		"DEBUG:\n"+
+		"   -verbose  Verbose output\n"+
+		"   -debug    Debug output\n"+
+		"\n"+
+		"OUTPUT:\n"+
+		"   -format string  Output format\n"+
+		"   -output string  Output file\n")
+}
@@ -0,0 +1,46 @@
+package goflags
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+)
+
+// decodeHCLConfig decodes a HashiCorp HCL config file into config data.
+//
+// Blocks are decoded as nested maps holding the flags of the command or group
+// they are named after, like the maps of YAML config files.
+func decodeHCLConfig(content []byte) (map[string]interface{}, map[string]int, error) {
+	file, err := hcl.ParseBytes(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var decoded map[string]interface{}
+	if err := hcl.DecodeObject(&decoded, file); err != nil {
+		return nil, nil, err
+	}
+
+	lines := make(map[string]int)
+	if list, ok := file.Node.(*ast.ObjectList); ok {
+		collectHCLKeyLines(list, "", lines)
+	}
+	return decoded, lines, nil
+}
+
+// collectHCLKeyLines records the lines of the attributes and blocks by their dotted path
+func collectHCLKeyLines(list *ast.ObjectList, prefix string, lines map[string]int) {
+	for _, item := range list.Items {
+		path := prefix
+		for _, key := range item.Keys {
+			path += strings.Trim(key.Token.Text, "\"") + "."
+		}
+		path = strings.TrimSuffix(path, ".")
+
+		lines[path] = item.Pos().Line
+		if object, ok := item.Val.(*ast.ObjectType); ok {
+			collectHCLKeyLines(object.List, path+".", lines)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package goflags
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeHCLConfig(t *testing.T) {
+	data, lines, err := decodeHCLConfig([]byte(`
+threads = 10
+
+output {
+  format = "json"
+
+  file {
+    path = "results.json"
+  }
+}
+
+input {
+  target = ["a.com", "b.com"]
+}
+`))
+	require.Nil(t, err, "could not decode hcl config")
+	require.Equal(t, map[string]interface{}{
+		"threads": 10,
+		"output": []map[string]interface{}{{
+			"format": "json",
+			"file": []map[string]interface{}{{
+				"path": "results.json",
+			}},
+		}},
+		"input": []map[string]interface{}{{
+			"target": []interface{}{"a.com", "b.com"},
+		}},
+	}, data)
+	require.Equal(t, 2, lines["threads"])
+	require.Equal(t, 8, lines["output.file.path"])
+
+	_, _, err = decodeHCLConfig([]byte("output {"))
+	require.NotNil(t, err, "could decode invalid hcl")
+}
+
+func TestMergeHCLConfigFile(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, "config.hcl")
+	err = ioutil.WriteFile(configFile, []byte("rate-limit {\n  threads = 25\n}\nverbose = true\ntarget = [\"a.com\", \"b.com\"]"), os.ModePerm)
+	require.Nil(t, err, "could not write hcl config")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var threads int
+	var targets StringSlice
+	var verbose bool
+	flagSet.IntVar(&threads, "threads", 5, "Threads")
+	flagSet.StringSliceVar(&targets, "target", nil, "Targets")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flagSet.SetGroup("rate-limit", "Rate limit")
+
+	err = flagSet.MergeConfigFile(configFile)
+	require.Nil(t, err, "could not merge hcl config")
+	require.Equal(t, 25, threads)
+	require.Equal(t, StringSlice{"a.com", "b.com"}, targets)
+	require.True(t, verbose)
+
+	tearDown(t.Name())
+}
+
+func TestHCLConfigBlocks(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var top, threads int
+	var file string
+	flagSet.IntVar(&top, "threads", 1, "Threads")
+	flagSet.StringVar(&file, "file", "", "Output file").Group("output")
+	flagSet.SetGroup("output", "Output")
+	scan := flagSet.NewCommand("scan", "Scan the targets")
+	scan.IntVar(&threads, "threads", 2, "Threads")
+
+	configFile := filepath.Join(tempDir, "config.hcl")
+	err = ioutil.WriteFile(configFile, []byte("threads = 3\noutput {\n  file = \"out.txt\"\n}\nscan {\n  threads = 9\n}\n"), os.ModePerm)
+	require.Nil(t, err, "could not write hcl config")
+	flagSet.SetConfigSearchPaths(configFile)
+	err = flagSet.ParseArgs([]string{"scan"})
+	require.Nil(t, err, "could not parse with hcl config")
+	require.Equal(t, 3, top, "command block was applied to the parent")
+	require.Equal(t, 9, threads, "command block was not applied to the command")
+	require.Equal(t, "out.txt", file, "group block was not applied")
+
+	err = ioutil.WriteFile(configFile, []byte("file = \"a.txt\"\noutput {\n  file = \"b.txt\"\n}\n"), os.ModePerm)
+	require.Nil(t, err, "could not write hcl config")
+	err = flagSet.MergeConfigFile(configFile)
+	require.NotNil(t, err, "could set a key repeated across blocks")
+	require.Contains(t, err.Error(), "already set by file")
+}
@@ -0,0 +1,65 @@
+package goflags
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// helpFormatJSON is the help format writing the UsageData as JSON
+const helpFormatJSON = "json"
+
+// helpAllFlagName is the name of the built-in flag showing the usage of all the flags
+const helpAllFlagName = "help-all"
+
+// usageMode is the amount of details shown in the usage
+type usageMode int
+
+const (
+	usageFull    usageMode = iota // the usage of the flags which are not hidden
+	usageCompact                  // the first line of the usage of the flags which are not hidden
+	usageAll                      // the usage and long description of all the flags, including hidden ones and groups
+)
+
+// requestedHelp returns the first of the -h, -help or -help-all flags among the
+// flags of the arguments, unless the FlagSet defines a flag with the same name.
+// The values following the flags which take one are skipped.
+func (flagSet *FlagSet) requestedHelp(args []string) (flagArg, bool) {
+	for i := 0; i < len(args); i++ {
+		parsed, ok := parseFlagArg(args[i])
+		if !ok {
+			break
+		}
+		if data, ok := flagSet.flagKeys.values[parsed.name]; ok && !parsed.hasValue && flagSet.takesValue(data) {
+			i++
+			continue
+		}
+		switch parsed.name {
+		case "h", "help", helpAllFlagName:
+			if flagSet.CommandLine().Lookup(parsed.name) == nil {
+				return parsed, true
+			}
+		}
+	}
+	return flagArg{}, false
+}
+
+// usageModeOf returns the usage mode requested with the help flag
+func (flagSet *FlagSet) usageModeOf(help flagArg) usageMode {
+	switch {
+	case help.name == helpAllFlagName:
+		return usageAll
+	case help.name == "h" && flagSet.CompactHelp:
+		return usageCompact
+	}
+	return usageFull
+}
+
+// writeUsageJSON writes the UsageData of the FlagSet as indented JSON
+func (flagSet *FlagSet) writeUsageJSON() {
+	output := flagSet.CommandLine().Output()
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(flagSet.UsageData()); err != nil {
+		fmt.Fprintf(output, "could not render usage: %s\n", err)
+	}
+}
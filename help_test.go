@@ -0,0 +1,110 @@
+package goflags
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelpJSON(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var output, format string
+	var threads int
+	flagSet.IntVarP(&threads, "threads", "t", 10, "Threads").Env("TOOL_THREADS")
+	flagSet.CreateGroup("output", "Output",
+		flagSet.StringVarP(&output, "output", "o", "", "Output file"),
+		flagSet.EnumVar(&format, "format", "json", []string{"json", "yaml"}, "Output format"),
+	)
+	flagSet.RequiredWhen("output", "format")
+	flagSet.AddExample("scan a host", "tool example.com")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	err := flagSet.ParseArgs([]string{"-help=json"})
+	require.Equal(t, flag.ErrHelp, err)
+
+	var data UsageData
+	require.Nil(t, json.Unmarshal(usage.Bytes(), &data), "could not decode help: %s", usage.String())
+	require.Len(t, data.Groups, 2)
+	require.Equal(t, &FlagInfo{Name: "threads", Short: "t", Long: "threads", Type: "int", Usage: "Threads", DefaultValue: "10", Value: "10", EnvNames: []string{"TOOL_THREADS"}}, data.Groups[0].Flags[0])
+	require.Equal(t, "OUTPUT", data.Groups[1].Title)
+	require.Equal(t, []string{"json", "yaml"}, data.Groups[1].Flags[1].Choices)
+	require.Equal(t, []UsageRequirement{{Flag: "output", Reason: "when -format is provided"}}, data.Constraints.Conditional)
+	require.Equal(t, []UsageExample{{Description: "scan a host", Command: "tool example.com"}}, data.Examples)
+
+	usage.Reset()
+	err = flagSet.ParseArgs([]string{"-o", "out.txt", "-help=json"})
+	require.Equal(t, flag.ErrHelp, err)
+	require.Nil(t, json.Unmarshal(usage.Bytes(), &data), "help after a flag value was not json: %s", usage.String())
+
+	usage.Reset()
+	err = flagSet.ParseArgs([]string{"-help"})
+	require.Equal(t, flag.ErrHelp, err)
+	require.Contains(t, usage.String(), "Flags:\n")
+}
+
+func TestHelpModes(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.CompactHelp = true
+	var threads, debugDelay int
+	var trace bool
+	flagSet.IntVar(&threads, "threads", 10, "Threads\nused to send the requests")
+	flagSet.IntVar(&debugDelay, "debug-delay", 0, "Delay between requests").Hidden()
+	flagSet.CreateGroup("advanced", "Advanced", flagSet.BoolVar(&trace, "trace", false, "Trace requests"))
+	flagSet.HideGroup("advanced")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	err := flagSet.ParseArgs([]string{"-h"})
+	require.Equal(t, flag.ErrHelp, err)
+	require.Contains(t, usage.String(), "Flags:\n   -threads int          Threads\n")
+	require.NotContains(t, usage.String(), "used to send the requests")
+	require.NotContains(t, usage.String(), "(default 10)")
+
+	usage.Reset()
+	err = flagSet.ParseArgs([]string{"-help"})
+	require.Equal(t, flag.ErrHelp, err)
+	require.Contains(t, usage.String(), "used to send the requests")
+	require.Contains(t, usage.String(), "(default 10)")
+	require.NotContains(t, usage.String(), "debug-delay")
+	require.NotContains(t, usage.String(), "ADVANCED:")
+
+	usage.Reset()
+	err = flagSet.ParseArgs([]string{"--help-all"})
+	require.Equal(t, flag.ErrHelp, err)
+	require.Contains(t, usage.String(), "-debug-delay")
+	require.Contains(t, usage.String(), "ADVANCED:\n   -trace  Trace requests\n")
+}
+
+func TestLongDescription(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var retries, threads int
+	flagSet.IntVar(&retries, "retries", 1, "Retries of failed requests").LongDescription("Failed requests are retried with an exponential backoff.\n\nTimeouts are not retried.")
+	flagSet.IntVar(&threads, "threads", 10, "Threads")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	err := flagSet.ParseArgs([]string{"-help"})
+	require.Equal(t, flag.ErrHelp, err)
+	require.NotContains(t, usage.String(), "exponential backoff")
+
+	usage.Reset()
+	err = flagSet.ParseArgs([]string{"-help-all"})
+	require.Equal(t, flag.ErrHelp, err)
+	require.Contains(t, usage.String(), "Flags:\n"+
+		"   -retries int          Retries of failed requests (default 1)\n"+
+		"                         Failed requests are retried with an exponential backoff.\n"+
+		"                         \n"+
+		"                         Timeouts are not retried.\n"+
+		"   -threads int          Threads (default 10)\n")
+
+	markdown := &bytes.Buffer{}
+	require.Nil(t, flagSet.GenerateMarkdown(markdown), "could not generate markdown")
+	require.Contains(t, markdown.String(), "| Retries of failed requests<br><br>Failed requests are retried with an exponential backoff.<br><br>Timeouts are not retried. |\n")
+}
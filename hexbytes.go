@@ -0,0 +1,79 @@
+package goflags
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// hexBytesValue is a flag.Value decoding a hex string into bytes
+type hexBytesValue struct {
+	field    *[]byte
+	min, max int
+}
+
+func (value *hexBytesValue) String() string {
+	if value.field == nil {
+		return ""
+	}
+	return hex.EncodeToString(*value.field)
+}
+
+// Type returns the name of the type of the value shown in the usage
+func (value *hexBytesValue) Type() string {
+	return "hex"
+}
+
+// Set decodes the hex string, optionally prefixed with 0x, checking its length in bytes.
+func (value *hexBytesValue) Set(input string) error {
+	input = strings.TrimPrefix(strings.TrimPrefix(input, "0x"), "0X")
+	decoded, err := hex.DecodeString(input)
+	if err != nil {
+		return errors.Wrap(err, "could not decode hex value")
+	}
+	if err := value.checkLength(len(decoded)); err != nil {
+		return err
+	}
+	*value.field = decoded
+	return nil
+}
+
+func (value *hexBytesValue) checkLength(length int) error {
+	switch {
+	case value.min > 0 && value.min == value.max && length != value.min:
+		return errors.Errorf("value must be exactly %d bytes, got %d", value.min, length)
+	case value.min > 0 && length < value.min:
+		return errors.Errorf("value must be at least %d bytes, got %d", value.min, length)
+	case value.max > 0 && length > value.max:
+		return errors.Errorf("value must be at most %d bytes, got %d", value.max, length)
+	}
+	return nil
+}
+
+// HexBytesVarP adds a hex encoded bytes flag with a shortname and longname.
+// The decoded value must be between min and max bytes long, where 0 means no limit.
+func (flagSet *FlagSet) HexBytesVarP(field *[]byte, long, short string, defaultValue []byte, min, max int, usage string) *FlagData {
+	*field = defaultValue
+
+	flagData := &FlagData{
+		usage:        usage,
+		short:        short,
+		long:         long,
+		defaultValue: hex.EncodeToString(defaultValue),
+	}
+	return flagSet.addFlag(&hexBytesValue{field: field, min: min, max: max}, flagData, short, long)
+}
+
+// HexBytesVar adds a hex encoded bytes flag with a longname.
+// The decoded value must be between min and max bytes long, where 0 means no limit.
+func (flagSet *FlagSet) HexBytesVar(field *[]byte, long string, defaultValue []byte, min, max int, usage string) *FlagData {
+	*field = defaultValue
+
+	flagData := &FlagData{
+		usage:        usage,
+		long:         long,
+		defaultValue: hex.EncodeToString(defaultValue),
+	}
+	return flagSet.addFlag(&hexBytesValue{field: field, min: min, max: max}, flagData, long)
+}
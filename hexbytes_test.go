@@ -0,0 +1,37 @@
+package goflags
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHexBytesVar(t *testing.T) {
+	tearDown(t.Name())
+	flag.CommandLine.Init(t.Name(), flag.ContinueOnError)
+	flag.CommandLine.SetOutput(ioutil.Discard)
+
+	flagSet := NewFlagSet()
+	var key, nonce []byte
+	flagSet.HexBytesVarP(&key, "key", "k", nil, 4, 4, "Encryption key")
+	flagSet.HexBytesVar(&nonce, "nonce", []byte{0xab}, 0, 2, "Nonce")
+	require.Equal(t, []byte{0xab}, nonce, "could not get default value")
+
+	err := flag.CommandLine.Parse([]string{"-k", "0xDEADbeef", "-nonce", "0102"})
+	require.Nil(t, err, "could not parse hex values")
+	require.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, key)
+	require.Equal(t, []byte{0x01, 0x02}, nonce)
+
+	for _, args := range [][]string{
+		{"-k", "deadbe"},
+		{"-k", "zz"},
+		{"-nonce", "010203"},
+	} {
+		err = flag.CommandLine.Parse(args)
+		require.NotNil(t, err, "could parse invalid value %v", args)
+	}
+
+	tearDown(t.Name())
+}
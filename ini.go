@@ -0,0 +1,77 @@
+package goflags
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// decodeINIConfig decodes an INI config file into config data.
+//
+// The keys before the first section are flag names, while each section is
+// decoded as a nested map, holding the flags of the command or group it is
+// named after, like the maps of YAML config files. Repeated keys of a section
+// set slice values.
+func decodeINIConfig(content []byte) (map[string]interface{}, map[string]int, error) {
+	data := make(map[string]interface{})
+	lines := make(map[string]int)
+
+	section, prefix := data, ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "["), "]"))
+			if !strings.HasSuffix(line, "]") || name == "" {
+				return nil, nil, errors.Errorf("invalid section on line %d: %s", lineNumber, line)
+			}
+			if _, ok := data[name].(map[string]interface{}); !ok {
+				if _, ok := data[name]; ok {
+					return nil, nil, errors.Errorf("section %s on line %d is also a key", name, lineNumber)
+				}
+				data[name] = make(map[string]interface{})
+				lines[name] = lineNumber
+			}
+			section, prefix = data[name].(map[string]interface{}), name+"."
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, nil, errors.Errorf("invalid key on line %d: %s", lineNumber, line)
+		}
+		key := strings.TrimSpace(parts[0])
+		value := unquoteINIValue(strings.TrimSpace(parts[1]))
+
+		switch existing := section[key].(type) {
+		case nil:
+			section[key] = value
+			lines[prefix+key] = lineNumber
+		case []interface{}:
+			section[key] = append(existing, value)
+		case map[string]interface{}:
+			return nil, nil, errors.Errorf("key %s on line %d is also a section", key, lineNumber)
+		default:
+			section[key] = []interface{}{existing, value}
+		}
+	}
+	return data, lines, scanner.Err()
+}
+
+func unquoteINIValue(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+	}
+	if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,107 @@
+package goflags
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeINIConfig(t *testing.T) {
+	data, lines, err := decodeINIConfig([]byte(`
+; global options
+threads = 10
+
+[output]
+# output options
+format = "json lines"
+silent = true
+
+[input]
+target = a.com
+target = 'b.com'
+target = c.com
+`))
+	require.Nil(t, err, "could not decode ini config")
+	require.Equal(t, map[string]interface{}{
+		"threads": "10",
+		"output": map[string]interface{}{
+			"format": "json lines",
+			"silent": "true",
+		},
+		"input": map[string]interface{}{
+			"target": []interface{}{"a.com", "b.com", "c.com"},
+		},
+	}, data)
+	require.Equal(t, 3, lines["threads"])
+	require.Equal(t, 11, lines["input.target"])
+
+	_, _, err = decodeINIConfig([]byte("[output\nformat = json"))
+	require.NotNil(t, err, "could decode invalid section")
+	_, _, err = decodeINIConfig([]byte("format json"))
+	require.NotNil(t, err, "could decode invalid key")
+}
+
+func TestMergeINIConfigFile(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, "config.ini")
+	err = ioutil.WriteFile(configFile, []byte("[rate-limit]\nthreads = 25\n[input]\ntarget = a.com\ntarget = b.com\nverbose = true"), os.ModePerm)
+	require.Nil(t, err, "could not write ini config")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var threads int
+	var targets StringSlice
+	var verbose bool
+	flagSet.IntVar(&threads, "threads", 5, "Threads")
+	flagSet.StringSliceVar(&targets, "target", nil, "Targets")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flagSet.SetGroup("rate-limit", "Rate limit")
+	flagSet.SetGroup("input", "Input")
+
+	err = flagSet.MergeConfigFile(configFile)
+	require.Nil(t, err, "could not merge ini config")
+	require.Equal(t, 25, threads)
+	require.Equal(t, StringSlice{"a.com", "b.com"}, targets)
+	require.True(t, verbose)
+
+	tearDown(t.Name())
+}
+
+func TestINIConfigSections(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var top, threads int
+	var file string
+	flagSet.IntVar(&top, "threads", 1, "Threads")
+	flagSet.StringVar(&file, "file", "", "Output file").Group("output")
+	flagSet.SetGroup("output", "Output")
+	scan := flagSet.NewCommand("scan", "Scan the targets")
+	scan.IntVar(&threads, "threads", 2, "Threads")
+
+	configFile := filepath.Join(tempDir, "config.ini")
+	err = ioutil.WriteFile(configFile, []byte("threads = 3\n[output]\nfile = out.txt\n[scan]\nthreads = 9\n"), os.ModePerm)
+	require.Nil(t, err, "could not write ini config")
+	flagSet.SetConfigSearchPaths(configFile)
+	err = flagSet.ParseArgs([]string{"scan"})
+	require.Nil(t, err, "could not parse with ini config")
+	require.Equal(t, 3, top, "command section was applied to the parent")
+	require.Equal(t, 9, threads, "command section was not applied to the command")
+	require.Equal(t, "out.txt", file, "group section was not applied")
+
+	err = ioutil.WriteFile(configFile, []byte("file = a.txt\n[output]\nfile = b.txt\n"), os.ModePerm)
+	require.Nil(t, err, "could not write ini config")
+	err = flagSet.MergeConfigFile(configFile)
+	require.NotNil(t, err, "could set a key repeated across sections")
+	require.Contains(t, err.Error(), "already set by file")
+}
@@ -0,0 +1,51 @@
+package goflags
+
+import (
+	"github.com/pkg/errors"
+	"github.com/zalando/go-keyring"
+)
+
+// UseKeyring makes Parse read the values of sensitive flags which were not provided
+// otherwise from the OS keyring, where they are stored under the application name
+// and the long name of the flag, for example with StoreKeyringValue.
+//
+// Flags are left unset when the keyring can't be reached, like on headless systems.
+func (flagSet *FlagSet) UseKeyring() {
+	flagSet.keyringEnabled = true
+}
+
+// StoreKeyringValue stores the value of a flag in the OS keyring, to be read by Parse
+// when the keyring is used.
+func (flagSet *FlagSet) StoreKeyringValue(name, value string) error {
+	if err := keyring.Set(flagSet.appName(), flagSet.canonicalName(name), value); err != nil {
+		return errors.Wrapf(err, "could not store -%s in keyring", name)
+	}
+	return nil
+}
+
+// readKeyringValues sets the sensitive flags still having their default value from the keyring
+func (flagSet *FlagSet) readKeyringValues() error {
+	if !flagSet.keyringEnabled {
+		return nil
+	}
+	var err error
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if err != nil || !data.sensitive || key != data.name() || flagSet.Source(key).Kind != SourceDefault {
+			return
+		}
+		currentFlag := flagSet.CommandLine().Lookup(key)
+		if currentFlag == nil {
+			return
+		}
+		value, getErr := keyring.Get(flagSet.appName(), key)
+		if getErr != nil {
+			return
+		}
+		if err = unwrapValue(currentFlag.Value).Set(value); err != nil {
+			err = errors.Wrapf(err, "invalid value for -%s from keyring", key)
+			return
+		}
+		flagSet.setSource(key, Source{Kind: SourceKeyring})
+	})
+	return err
+}
@@ -0,0 +1,36 @@
+package goflags
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeyring(t *testing.T) {
+	keyring.MockInit()
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.SetConfigFilePath("")
+	flagSet.UseKeyring()
+	var token, secret, output string
+	flagSet.StringVarP(&token, "token", "t", "", "API token").Sensitive()
+	flagSet.StringVar(&secret, "secret", "", "Secret").Sensitive()
+	flagSet.StringVar(&output, "output", "", "Output file")
+
+	require.Nil(t, flagSet.StoreKeyringValue("t", "keyring token"), "could not store value")
+	require.Nil(t, flagSet.StoreKeyringValue("secret", "keyring secret"), "could not store value")
+	require.Nil(t, flagSet.StoreKeyringValue("output", "keyring.txt"), "could not store value")
+
+	os.Args = []string{os.Args[0], "-secret", "cli secret"}
+	err := flagSet.Parse()
+	require.Nil(t, err, "could not parse flags")
+	require.Equal(t, "keyring token", token)
+	require.Equal(t, Source{Kind: SourceKeyring}, flagSet.Source("token"))
+	require.Equal(t, "cli secret", secret, "could not prefer the cli")
+	require.Empty(t, output, "could read non sensitive flag from keyring")
+
+	tearDown(t.Name())
+}
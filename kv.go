@@ -0,0 +1,131 @@
+package goflags
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MergeConsulConfig merges the flag values stored below a prefix of the Consul
+// KV store at address, like http://127.0.0.1:8500. Each key below the prefix
+// names a flag, nested keys like profiles/prod/threads being nested config values.
+//
+// The Consul ACL token can be provided with an X-Consul-Token header in the options.
+func (flagSet *FlagSet) MergeConsulConfig(address, prefix string, options *RemoteConfigOptions) error {
+	if options == nil {
+		options = &RemoteConfigOptions{}
+	}
+	prefix = strings.TrimPrefix(prefix, "/")
+	request, err := newRemoteConfigRequest(http.MethodGet, strings.TrimSuffix(address, "/")+"/v1/kv/"+prefix+"?recurse=true", nil, options)
+	if err != nil {
+		return err
+	}
+
+	var pairs []struct {
+		Key   string
+		Value []byte
+	}
+	if err := doKVRequest(request, options, &pairs); err != nil {
+		return errors.Wrap(err, "could not read consul config")
+	}
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		values[strings.TrimPrefix(pair.Key, prefix)] = string(pair.Value)
+	}
+	return flagSet.mergeKVConfig(values, "consul:"+prefix)
+}
+
+// MergeEtcdConfig merges the flag values stored below a prefix of the etcd
+// cluster at address, like http://127.0.0.1:2379, using its v3 JSON gateway.
+// Keys are mapped to flags like with MergeConsulConfig.
+//
+// An etcd auth token can be provided with an Authorization header in the options.
+func (flagSet *FlagSet) MergeEtcdConfig(address, prefix string, options *RemoteConfigOptions) error {
+	if options == nil {
+		options = &RemoteConfigOptions{}
+	}
+	body, err := json.Marshal(struct {
+		Key      []byte `json:"key"`
+		RangeEnd []byte `json:"range_end"`
+	}{Key: []byte(prefix), RangeEnd: prefixRangeEnd([]byte(prefix))})
+	if err != nil {
+		return errors.Wrap(err, "could not marshal etcd request")
+	}
+	request, err := newRemoteConfigRequest(http.MethodPost, strings.TrimSuffix(address, "/")+"/v3/kv/range", bytes.NewReader(body), options)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	var response struct {
+		Kvs []struct {
+			Key   []byte `json:"key"`
+			Value []byte `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := doKVRequest(request, options, &response); err != nil {
+		return errors.Wrap(err, "could not read etcd config")
+	}
+	values := make(map[string]string, len(response.Kvs))
+	for _, kv := range response.Kvs {
+		values[strings.TrimPrefix(string(kv.Key), prefix)] = string(kv.Value)
+	}
+	return flagSet.mergeKVConfig(values, "etcd:"+prefix)
+}
+
+// doKVRequest sends a request to a key-value store and decodes its JSON response,
+// a missing prefix having no values.
+func doKVRequest(request *http.Request, options *RemoteConfigOptions, out interface{}) error {
+	response, err := remoteConfigClient(options).Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status %s", response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+// prefixRangeEnd returns the end of the etcd key range holding the keys with the prefix
+func prefixRangeEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return []byte{0} // every key
+}
+
+// mergeKVConfig merges the values read from a key-value store, the keys being
+// relative to the prefix and using / to separate nested config values.
+func (flagSet *FlagSet) mergeKVConfig(values map[string]string, source string) error {
+	data := make(map[string]interface{})
+	for key, value := range values {
+		if strings.HasSuffix(key, "/") {
+			continue // folder
+		}
+		parts := strings.Split(strings.Trim(key, "/"), "/")
+		current := data
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				current[part] = next
+			}
+			current = next
+		}
+		current[parts[len(parts)-1]] = value
+	}
+	return flagSet.mergeConfigData(data, source, nil)
+}
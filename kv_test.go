@@ -0,0 +1,73 @@
+package goflags
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeConsulConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/kv/tool/", r.URL.Path)
+		require.Equal(t, "secret", r.Header.Get("X-Consul-Token"))
+		_, _ = w.Write([]byte(`[
+			{"Key": "tool/", "Value": null},
+			{"Key": "tool/threads", "Value": "MTA="},
+			{"Key": "tool/targets", "Value": "YS5jb20sYi5jb20="}
+		]`))
+	}))
+	defer server.Close()
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var threads int
+	var targets StringSlice
+	flagSet.IntVar(&threads, "threads", 1, "Threads")
+	flagSet.StringSliceVar(&targets, "targets", nil, "Targets")
+
+	err := flagSet.MergeConsulConfig(server.URL, "tool/", &RemoteConfigOptions{Headers: map[string]string{"X-Consul-Token": "secret"}})
+	require.Nil(t, err, "could not merge consul config")
+	require.Equal(t, 10, threads)
+	require.Equal(t, StringSlice{"a.com", "b.com"}, targets)
+
+	tearDown(t.Name())
+}
+
+func TestMergeEtcdConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v3/kv/range", r.URL.Path)
+		var request struct {
+			Key      []byte `json:"key"`
+			RangeEnd []byte `json:"range_end"`
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&request))
+		require.Equal(t, "/tool/", string(request.Key))
+		require.Equal(t, "/tool0", string(request.RangeEnd))
+		_, _ = w.Write([]byte(`{"kvs": [
+			{"key": "L3Rvb2wvdGhyZWFkcw==", "value": "MTA="},
+			{"key": "L3Rvb2wvcHJvZmlsZXMvcHJvZC90aHJlYWRz", "value": "NTA="}
+		]}`))
+	}))
+	defer server.Close()
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.profile = "prod"
+	var threads int
+	flagSet.IntVar(&threads, "threads", 1, "Threads")
+
+	err := flagSet.MergeEtcdConfig(server.URL, "/tool/", nil)
+	require.Nil(t, err, "could not merge etcd config")
+	require.Equal(t, 50, threads)
+
+	tearDown(t.Name())
+}
+
+func TestPrefixRangeEnd(t *testing.T) {
+	require.Equal(t, []byte("tool0"), prefixRangeEnd([]byte("tool/")))
+	require.Equal(t, []byte{'b'}, prefixRangeEnd([]byte{'a', 0xff}))
+	require.Equal(t, []byte{0}, prefixRangeEnd(nil))
+}
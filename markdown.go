@@ -0,0 +1,66 @@
+package goflags
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GenerateMarkdown writes a reference of the flags shown in the usage as
+// markdown tables, one for the flags without a group followed by one for
+// every group, so that it can be included in the documentation.
+func (flagSet *FlagSet) GenerateMarkdown(w io.Writer) error {
+	longPrefix := "-"
+	if flagSet.DoubleDashUsage {
+		longPrefix = "--"
+	}
+
+	var builder strings.Builder
+	for i, group := range flagSet.UsageData().Groups {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		if group.Title != "" {
+			fmt.Fprintf(&builder, "### %s\n\n", group.Title)
+		}
+		builder.WriteString("| Flag | Type | Default | Description |\n")
+		builder.WriteString("|------|------|---------|-------------|\n")
+		for _, info := range group.Flags {
+			var names []string
+			if info.Short != "" {
+				names = append(names, "-"+info.Short)
+			}
+			if info.Long != "" {
+				names = append(names, longPrefix+info.Long)
+			}
+			description := info.Usage
+			if info.LongDescription != "" {
+				description += "\n\n" + strings.TrimSpace(info.LongDescription)
+			}
+			fmt.Fprintf(&builder, "| `%s` | %s | %s | %s |\n", strings.Join(names, ", "), info.Type, markdownDefault(info), markdownCell(description))
+		}
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// markdownDefault returns the default value of the flag as shown in the markdown reference
+func markdownDefault(info *FlagInfo) string {
+	switch {
+	case info.HideDefault:
+		return ""
+	case info.DefaultText != "":
+		return markdownCell(info.DefaultText)
+	case info.DefaultValue == "":
+		return ""
+	case info.Sensitive:
+		return redactedValue
+	}
+	return "`" + strings.ReplaceAll(info.DefaultValue, "`", "'") + "`"
+}
+
+// markdownCell escapes the text to be shown in a cell of a markdown table
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
@@ -0,0 +1,34 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateMarkdown(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var output, token string
+	var threads int
+	var debug bool
+	flagSet.IntVarP(&threads, "threads", "t", 0, "Threads").DefaultText("number of CPUs")
+	flagSet.StringVar(&token, "token", "secret", "API token").Sensitive()
+	flagSet.BoolVar(&debug, "debug", false, "Debug output").Hidden()
+	flagSet.CreateGroup("output", "Output", flagSet.StringVarP(&output, "output", "o", "out.txt", "Output file | path"))
+
+	markdown := &bytes.Buffer{}
+	require.Nil(t, flagSet.GenerateMarkdown(markdown), "could not generate markdown")
+	require.Equal(t, "| Flag | Type | Default | Description |\n"+
+		"|------|------|---------|-------------|\n"+
+		"| `-t, -threads` | int | number of CPUs | Threads |\n"+
+		"| `-token` | string | "+redactedValue+" | API token |\n"+
+		"\n"+
+		"### OUTPUT\n"+
+		"\n"+
+		"| Flag | Type | Default | Description |\n"+
+		"|------|------|---------|-------------|\n"+
+		"| `-o, -output` | string | `out.txt` | Output file \\| path |\n", markdown.String())
+}
@@ -0,0 +1,64 @@
+package goflags
+
+import (
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// configVersionKey is the config key holding the version of a config file
+const configVersionKey = "configVersion"
+
+// ConfigMigration upgrades the decoded values of a config file to the next version,
+// for example by moving the value of a renamed flag to its new key.
+type ConfigMigration func(data map[string]interface{}) error
+
+// SetConfigVersion sets the current version of the config files, written in the
+// generated config file. Older config files are upgraded using the config migrations.
+func (flagSet *FlagSet) SetConfigVersion(version int) {
+	flagSet.configVersion = version
+}
+
+// AddConfigMigration registers the migration upgrading config files from
+// version to version+1. Config files without a version are at version 0.
+func (flagSet *FlagSet) AddConfigMigration(version int, migration ConfigMigration) {
+	if flagSet.configMigrations == nil {
+		flagSet.configMigrations = make(map[int]ConfigMigration)
+	}
+	flagSet.configMigrations[version] = migration
+}
+
+// migrateConfig upgrades the config data to the current config version,
+// reporting whether any migration was applied.
+func (flagSet *FlagSet) migrateConfig(filePath string, data map[string]interface{}) (bool, error) {
+	version, ok := data[configVersionKey].(int)
+	if _, exists := data[configVersionKey]; exists && !ok {
+		return false, errors.Errorf("invalid %s in %s", configVersionKey, filePath)
+	}
+	if version > flagSet.configVersion {
+		return false, errors.Errorf("config version %d of %s is newer than the supported version %d", version, filePath, flagSet.configVersion)
+	}
+	if version == flagSet.configVersion {
+		return false, nil
+	}
+
+	for ; version < flagSet.configVersion; version++ {
+		migration, ok := flagSet.configMigrations[version]
+		if !ok {
+			return false, errors.Errorf("no migration from config version %d for %s", version, filePath)
+		}
+		if err := migration(data); err != nil {
+			return false, errors.Wrapf(err, "could not migrate %s from config version %d", filePath, version)
+		}
+	}
+	data[configVersionKey] = flagSet.configVersion
+	return true, nil
+}
+
+// rewriteConfigFile writes the upgraded config data, backing up the original file
+func (flagSet *FlagSet) rewriteConfigFile(filePath string, data map[string]interface{}) error {
+	upgraded, err := yaml.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal upgraded config file")
+	}
+	return flagSet.saveConfigFile(filePath, upgraded, true)
+}
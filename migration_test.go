@@ -0,0 +1,66 @@
+package goflags
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestConfigMigration(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	original := "threads: 5\ntimeout: 10\n"
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte(original), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.SetConfigVersion(2)
+	flagSet.AddConfigMigration(0, func(data map[string]interface{}) error {
+		data["concurrency"] = data["threads"]
+		delete(data, "threads")
+		return nil
+	})
+	flagSet.AddConfigMigration(1, func(data map[string]interface{}) error {
+		data["timeout-seconds"] = data["timeout"]
+		delete(data, "timeout")
+		return nil
+	})
+
+	var concurrency, timeout int
+	flagSet.IntVar(&concurrency, "concurrency", 1, "Concurrency")
+	flagSet.IntVar(&timeout, "timeout-seconds", 1, "Timeout")
+	require.Contains(t, string(flagSet.generateDefaultConfig()), "\nconfigVersion: 2\n")
+
+	err = flagSet.MergeConfigFile(configFile)
+	require.Nil(t, err, "could not merge old config")
+	require.Equal(t, 5, concurrency)
+	require.Equal(t, 10, timeout)
+
+	backup, err := ioutil.ReadFile(configFile + ".bak")
+	require.Nil(t, err, "could not read config backup")
+	require.Equal(t, original, string(backup))
+
+	upgraded := make(map[string]interface{})
+	content, err := ioutil.ReadFile(configFile)
+	require.Nil(t, err, "could not read upgraded config")
+	require.Nil(t, yaml.Unmarshal(content, &upgraded))
+	require.Equal(t, map[string]interface{}{"configVersion": 2, "concurrency": 5, "timeout-seconds": 10}, upgraded)
+
+	flagSet.SetConfigVersion(3)
+	err = flagSet.MergeConfigFile(configFile)
+	require.NotNil(t, err, "could merge config without migration")
+
+	flagSet.SetConfigVersion(1)
+	err = flagSet.MergeConfigFile(configFile)
+	require.NotNil(t, err, "could merge newer config")
+
+	tearDown(t.Name())
+}
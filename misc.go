@@ -1,9 +1,10 @@
 package goflags
 
 import (
-	"github.com/pkg/errors"
-	"regexp"
 	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
 )
 
 // StringSlice is a slice of strings
@@ -23,24 +24,93 @@ func (stringSlice *StringSlice) Set(value string) error {
 	}
 }
 
-var multiValueValidator = regexp.MustCompile("('[^',]+?,.*?')|(\"[^\",]+?,.*?\")|(`[^,]+?,.*?`)")
+const quoteChars = "\"'`"
 
+// ToStringSlice splits a comma separated value into its lowercased items.
+//
+// Items can be wrapped in ', " or ` quotes to keep commas and spaces in them,
+// and a backslash escapes a following comma, quote or backslash.
 func ToStringSlice(value string) ([]string, error) {
-	if multiValueValidator.FindString(value) != "" {
-		return nil, errors.New("Supported values are: value1,value2 etc")
-	}
+	return splitSliceValue(strings.ToLower(value))
+}
 
-	value = strings.ToLower(value)
+// splitSliceValue splits a comma separated value into its items preserving their case.
+func splitSliceValue(value string) ([]string, error) {
+	runes := []rune(value)
 
 	var result []string
-	if strings.Contains(value, ",") {
-		slices := strings.Split(value, ",")
-		result = make([]string, 0, len(slices))
-		for _, slice := range slices {
-			result = append(result, strings.TrimSpace(strings.Trim(strings.TrimSpace(slice), "\"'`")))
+	item := &strings.Builder{}
+	quoted, closed := false, false
+
+	appendItem := func() {
+		if quoted {
+			result = append(result, item.String())
+		} else {
+			result = append(result, strings.TrimSpace(strings.Trim(strings.TrimSpace(item.String()), quoteChars)))
+		}
+		item.Reset()
+		quoted, closed = false, false
+	}
+
+	for i := 0; i < len(runes); i++ {
+		char := runes[i]
+		switch {
+		case closed && char == ',':
+			appendItem()
+		case closed && unicode.IsSpace(char):
+		case closed:
+			return nil, errors.Errorf("unexpected character %q after closing quote in %q", char, value)
+		case isSliceEscape(runes, i):
+			i++
+			item.WriteRune(runes[i])
+		case strings.ContainsRune(quoteChars, char) && strings.TrimSpace(item.String()) == "":
+			end := closingQuoteIndex(runes, i)
+			if end == -1 {
+				item.WriteRune(char) // unbalanced quotes are kept as literals
+				continue
+			}
+			item.Reset()
+			for i++; i < end; i++ {
+				if isSliceEscape(runes, i) {
+					i++
+				}
+				item.WriteRune(runes[i])
+			}
+			quoted, closed = true, true
+		case char == ',':
+			appendItem()
+		default:
+			item.WriteRune(char)
 		}
-	} else {
-		result = []string{value}
 	}
+	appendItem()
 	return result, nil
 }
+
+// isSliceEscape returns true if the rune at index is a backslash escaping the next one
+func isSliceEscape(runes []rune, index int) bool {
+	return runes[index] == '\\' && index+1 < len(runes) && strings.ContainsRune(",\\"+quoteChars, runes[index+1])
+}
+
+// closingQuoteIndex returns the index of the unescaped quote closing the one at index, or -1
+func closingQuoteIndex(runes []rune, index int) int {
+	for i := index + 1; i < len(runes); i++ {
+		if isSliceEscape(runes, i) {
+			i++
+			continue
+		}
+		if runes[i] == runes[index] {
+			return i
+		}
+	}
+	return -1
+}
+
+// quoteSliceItem quotes an item so that ToStringSlice parses it back unchanged.
+func quoteSliceItem(item string) string {
+	if item == strings.TrimSpace(item) && !strings.ContainsAny(item, ",\\"+quoteChars) {
+		return item
+	}
+	escaper := strings.NewReplacer("\\", "\\\\", "\"", "\\\"")
+	return "\"" + escaper.Replace(item) + "\""
+}
@@ -3,6 +3,8 @@ package goflags
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+
+	"gopkg.in/yaml.v2"
 )
 
 func Test_toStringSlice(t *testing.T) {
@@ -17,9 +19,13 @@ func Test_toStringSlice(t *testing.T) {
 		"\n  aa, \tbb,  cc\r   ":         expected,
 		"\n  \"aa', \t`bb',  \"cc\r`   ": nil,
 		"\"\n  aa', `\tbb',  \"cc\r`   ": nil,
-		"'aa,bb,cc'":                     nil,
-		"`aa,bb,cc`":                     nil,
-		"\"aa,bb,cc\"":                   nil,
+		"'aa,bb,cc'":                     []string{"aa,bb,cc"},
+		"`aa,bb,cc`":                     []string{"aa,bb,cc"},
+		"\"aa,bb,cc\"":                   []string{"aa,bb,cc"},
+		"\"x-key: a,b\", 'c d'":          []string{"x-key: a,b", "c d"},
+		"aa\\,bb,cc":                     []string{"aa,bb", "cc"},
+		"\"a\\\"b\", c\\d":               []string{"a\"b", "c\\d"},
+		"'aa' bb, cc":                    nil,
 	}
 	for input, expectedValue := range values {
 		t.Run(input, func(t *testing.T) {
@@ -34,3 +40,21 @@ func Test_toStringSlice(t *testing.T) {
 		})
 	}
 }
+
+func TestStringSliceRoundTrip(t *testing.T) {
+	items := StringSlice{"plain", "x-key: a,b", " spaced ", "quo\"te", "back\\slash,"}
+	for _, item := range items {
+		slice, err := ToStringSlice(quoteSliceItem(item))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{item}, slice)
+	}
+
+	var parsed []string
+	err := yaml.Unmarshal([]byte(items.createStringArrayDefaultValue()), &parsed)
+	assert.Nil(t, err)
+	for i, value := range parsed {
+		slice, err := ToStringSlice(value)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{items[i]}, slice)
+	}
+}
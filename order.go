@@ -0,0 +1,44 @@
+package goflags
+
+// UsageOrder is the order the flags are shown in the usage
+type UsageOrder int
+
+const (
+	// OrderInsertion shows the flags in the order they were registered, in their groups
+	OrderInsertion UsageOrder = iota
+	// OrderAlphabetical shows all the flags sorted by name, without groups
+	OrderAlphabetical
+	// OrderGroupAlphabetical shows the flags sorted by name in their groups
+	OrderGroupAlphabetical
+)
+
+// SetUsageOrder sets the order the flags are shown in the usage, OrderInsertion by default
+func (flagSet *FlagSet) SetUsageOrder(order UsageOrder) {
+	flagSet.usageOrder = order
+}
+
+// SetUsageComparator sets the function sorting the flags in their groups in the
+// usage, less reporting whether the first flag is shown before the second one.
+// It takes precedence over the order set with SetUsageOrder.
+func (flagSet *FlagSet) SetUsageComparator(less func(a, b *FlagInfo) bool) {
+	flagSet.usageComparator = less
+}
+
+// byName reports whether the first flag has a name sorted before the second one
+func byName(a, b *FlagData) bool {
+	return a.name() < b.name()
+}
+
+// usageLess returns the function sorting the flags of the group in the usage,
+// nil if they are shown in the order they were registered.
+func (flagSet *FlagSet) usageLess(group flagGroup) func(a, b *FlagData) bool {
+	switch {
+	case flagSet.usageComparator != nil:
+		return func(a, b *FlagData) bool {
+			return flagSet.usageComparator(flagSet.flagInfo(a), flagSet.flagInfo(b))
+		}
+	case flagSet.usageOrder == OrderGroupAlphabetical || group.sorted:
+		return byName
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageSortingOrder(t *testing.T) {
+	newFlagSet := func(t *testing.T) *FlagSet {
+		flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+		flagSet.SetConfigFilePath("")
+		var output, format string
+		var verbose, debug bool
+		flagSet.CreateGroup("output", "Output",
+			flagSet.StringVar(&output, "output", "", "Output file"),
+			flagSet.StringVar(&format, "format", "", "Output format"),
+		)
+		flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+		flagSet.BoolVar(&debug, "debug", false, "Debug output")
+		return flagSet
+	}
+	usage := func(flagSet *FlagSet) string {
+		output := &bytes.Buffer{}
+		flagSet.CommandLine().SetOutput(output)
+		flagSet.usageFunc()
+		return output.String()
+	}
+
+	t.Run("insertion", func(t *testing.T) {
+		require.Contains(t, usage(newFlagSet(t)), "Flags:\n"+
+			"   -verbose  Verbose output\n"+
+			"   -debug    Debug output\n"+
+			"\n"+
+			"OUTPUT:\n"+
+			"   -output string  Output file\n"+
+			"   -format string  Output format\n")
+	})
+	t.Run("alphabetical", func(t *testing.T) {
+		flagSet := newFlagSet(t)
+		flagSet.SetUsageOrder(OrderAlphabetical)
+		require.Contains(t, usage(flagSet), "Flags:\n"+
+			"   -debug          Debug output\n"+
+			"   -format string  Output format\n"+
+			"   -output string  Output file\n"+
+			"   -verbose        Verbose output\n")
+
+		var names []string
+		for _, group := range flagSet.UsageData().Groups {
+			for _, info := range group.Flags {
+				names = append(names, info.Name)
+			}
+		}
+		require.Equal(t, []string{"debug", "format", "output", "verbose"}, names)
+	})
+	t.Run("group-alphabetical", func(t *testing.T) {
+		flagSet := newFlagSet(t)
+		flagSet.SetUsageOrder(OrderGroupAlphabetical)
+		require.Contains(t, usage(flagSet), "Flags:\n"+
+			"   -debug    Debug output\n"+
+			"   -verbose  Verbose output\n"+
+			"\n"+
+			"OUTPUT:\n"+
+			"   -format string  Output format\n"+
+			"   -output string  Output file\n")
+	})
+	t.Run("comparator", func(t *testing.T) {
+		flagSet := newFlagSet(t)
+		flagSet.SetUsageOrder(OrderAlphabetical)
+		flagSet.SetUsageComparator(func(a, b *FlagInfo) bool { return a.Name > b.Name })
+		require.Contains(t, usage(flagSet), "Flags:\n"+
+			"   -verbose  Verbose output\n"+
+			"   -debug    Debug output\n"+
+			"\n"+
+			"OUTPUT:\n"+
+			"   -output string  Output file\n"+
+			"   -format string  Output format\n")
+	})
+}
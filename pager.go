@@ -0,0 +1,45 @@
+package goflags
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// terminalHeight returns the height of the output if it is a terminal,
+// or 0 if it isn't, replaced in tests
+var terminalHeight = func(output io.Writer) int {
+	file, ok := output.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return 0
+	}
+	_, height, err := term.GetSize(int(file.Fd()))
+	if err != nil {
+		return 0
+	}
+	return height
+}
+
+// pageUsage writes the usage through $PAGER if it has more lines than the
+// height of the output, or directly if it fits or the pager can't be started.
+func pageUsage(output io.Writer, usage string) {
+	pager := strings.Fields(os.Getenv("PAGER"))
+	height := terminalHeight(output)
+	if len(pager) == 0 || height == 0 || strings.Count(usage, "\n") < height {
+		fmt.Fprint(output, usage)
+		return
+	}
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdin = strings.NewReader(usage)
+	cmd.Stdout = output
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		fmt.Fprint(output, usage)
+		return
+	}
+	_ = cmd.Wait()
+}
@@ -0,0 +1,44 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagerUsage(t *testing.T) {
+	defer func(previous func(output io.Writer) bool) { isTerminal = previous }(isTerminal)
+	isTerminal = func(output io.Writer) bool { return true }
+	defer func(previous func(output io.Writer) int) { terminalHeight = previous }(terminalHeight)
+	height := 5
+	terminalHeight = func(output io.Writer) int { return height }
+	defer os.Setenv("PAGER", os.Getenv("PAGER"))
+	os.Setenv("PAGER", "tr a-z A-Z")
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.SetDescription("Test pager")
+	flagSet.PagerUsage = true
+	var output, format string
+	flagSet.StringVar(&output, "output", "", "Output file")
+	flagSet.StringVar(&format, "format", "", "Output format")
+	usage := func() string {
+		buffer := &bytes.Buffer{}
+		flagSet.CommandLine().SetOutput(buffer)
+		flagSet.usageFunc()
+		return buffer.String()
+	}
+
+	require.Contains(t, usage(), "-FORMAT STRING  OUTPUT FORMAT", "usage was not piped through the pager")
+
+	os.Setenv("PAGER", "goflags-missing-pager")
+	require.Contains(t, usage(), "-format string  Output format", "usage was not written when the pager can't be started")
+
+	os.Setenv("PAGER", "tr a-z A-Z")
+	height = 100
+	require.Contains(t, usage(), "-format string  Output format", "usage fitting the terminal was paged")
+}
@@ -0,0 +1,83 @@
+package goflags
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+// promptPassword reads a password from the terminal without echoing it.
+// It returns false if stdin is not a terminal and no prompt was shown.
+var promptPassword = func(prompt string) (string, bool, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", false, nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	return string(password), true, err
+}
+
+// PasswordVarP adds a password flag with a shortname and longname.
+//
+// When the flag is not provided the password is prompted for on the terminal,
+// and its value is never written to the generated config file.
+func (flagSet *FlagSet) PasswordVarP(field *string, long, short, usage string) *FlagData {
+	value := stdlibValue(func(set *flag.FlagSet) { set.StringVar(field, long, "", usage) })
+
+	flagData := &FlagData{
+		usage:        usage,
+		short:        short,
+		long:         long,
+		defaultValue: "",
+		password:     true,
+	}
+	return flagSet.addFlag(value, flagData, short, long)
+}
+
+// PasswordVar adds a password flag with a longname.
+//
+// When the flag is not provided the password is prompted for on the terminal,
+// and its value is never written to the generated config file.
+func (flagSet *FlagSet) PasswordVar(field *string, long, usage string) *FlagData {
+	value := stdlibValue(func(set *flag.FlagSet) { set.StringVar(field, long, "", usage) })
+
+	flagData := &FlagData{
+		usage:        usage,
+		long:         long,
+		defaultValue: "",
+		password:     true,
+	}
+	return flagSet.addFlag(value, flagData, long)
+}
+
+// promptPasswords prompts for the password flags that were not provided
+func (flagSet *FlagSet) promptPasswords() error {
+	var err error
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if err != nil || !data.password || key != data.name() {
+			return
+		}
+		currentFlag := flagSet.CommandLine().Lookup(key)
+		if currentFlag == nil || currentFlag.Value.String() != "" {
+			return
+		}
+
+		password, prompted, promptErr := promptPassword(fmt.Sprintf("%s: ", data.usage))
+		if promptErr != nil {
+			err = errors.Wrapf(promptErr, "could not read -%s", key)
+			return
+		}
+		if prompted {
+			if err = unwrapValue(currentFlag.Value).Set(password); err == nil {
+				flagSet.setSource(key, Source{Kind: SourcePrompt})
+			}
+		}
+	})
+	return err
+}
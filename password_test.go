@@ -0,0 +1,48 @@
+package goflags
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptPasswords(t *testing.T) {
+	defaultPrompt := promptPassword
+	defer func() { promptPassword = defaultPrompt }()
+
+	var prompts []string
+	promptPassword = func(prompt string) (string, bool, error) {
+		prompts = append(prompts, prompt)
+		return "secret", true, nil
+	}
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var password, token string
+	flagSet.PasswordVarP(&password, "password", "p", "Proxy password")
+	flagSet.PasswordVar(&token, "token", "API token")
+
+	err := flag.CommandLine.Parse([]string{"-token", "provided"})
+	require.Nil(t, err, "could not parse flags")
+	err = flagSet.promptPasswords()
+	require.Nil(t, err, "could not prompt passwords")
+
+	require.Equal(t, []string{"Proxy password: "}, prompts, "could not prompt only missing passwords")
+	require.Equal(t, "secret", password)
+	require.Equal(t, "provided", token)
+
+	promptPassword = func(prompt string) (string, bool, error) {
+		return "", false, nil
+	}
+	password = ""
+	err = flagSet.promptPasswords()
+	require.Nil(t, err, "could not skip prompt without terminal")
+	require.Equal(t, "", password)
+
+	config := string(flagSet.generateDefaultConfig())
+	require.NotContains(t, config, "password")
+	require.NotContains(t, config, "token")
+
+	tearDown(t.Name())
+}
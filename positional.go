@@ -0,0 +1,139 @@
+package goflags
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// positional is a named argument provided after the flags
+type positional struct {
+	field    *string
+	name     string
+	usage    string
+	required bool
+	initial  string
+
+	// variadic arguments take all the remaining arguments
+	variadic bool
+	values   *[]string
+	min, max int
+}
+
+// usageName returns the name of the positional argument as shown in the usage line
+func (arg *positional) usageName() string {
+	name := "[" + arg.name + "]"
+	if arg.required {
+		name = "<" + arg.name + ">"
+	}
+	if arg.variadic {
+		name += "..."
+	}
+	return name
+}
+
+// AddPositional adds a named argument provided after the flags, in the order the
+// arguments are added. Parse fails when a required argument is missing or when more
+// arguments than the added ones are provided.
+//
+// NOTE: AddPositional panics if a required argument follows an optional one,
+// unless the FlagSet is in library mode.
+func (flagSet *FlagSet) AddPositional(field *string, name, usage string, required bool) {
+	flagSet.addPositional(&positional{field: field, name: name, usage: usage, required: required, initial: *field})
+}
+
+// AddVariadicPositional adds a final named argument taking all the remaining arguments,
+// of which there must be at least min and at most max, unless max is 0.
+//
+// NOTE: AddVariadicPositional panics if it is not the last positional argument, or
+// if it has a minimum count and follows an optional argument, unless the FlagSet
+// is in library mode.
+func (flagSet *FlagSet) AddVariadicPositional(field *[]string, name, usage string, min, max int) {
+	flagSet.addPositional(&positional{values: field, name: name, usage: usage, required: min > 0, variadic: true, min: min, max: max})
+}
+
+// addPositional adds a positional argument, checking that it can follow the previous one
+func (flagSet *FlagSet) addPositional(arg *positional) {
+	if err := flagSet.checkFrozen(arg.name); err != nil {
+		flagSet.fail(err)
+		return
+	}
+	if count := len(flagSet.positionals); count > 0 {
+		previous := flagSet.positionals[count-1]
+		switch {
+		case previous.variadic:
+			flagSet.fail(errors.Errorf("argument %s can't follow variadic argument %s", arg.name, previous.name))
+			return
+		case arg.required && !previous.required:
+			flagSet.fail(errors.Errorf("required argument %s can't follow optional argument %s", arg.name, previous.name))
+			return
+		}
+	}
+	flagSet.positionals = append(flagSet.positionals, arg)
+}
+
+// bindPositionals sets the positional arguments from the arguments remaining after
+// the flags, up to the -- terminator starting the remainder.
+func (flagSet *FlagSet) bindPositionals() error {
+	if len(flagSet.positionals) == 0 {
+		return nil
+	}
+	args := flagSet.Args()
+	if flagSet.remainder != nil {
+		args = args[:len(args)-len(flagSet.remainder)]
+		if len(args) > 0 && args[len(args)-1] == "--" {
+			args = args[:len(args)-1]
+		}
+	}
+
+	var errs Errors
+	for i, arg := range flagSet.positionals {
+		if arg.variadic {
+			var values []string
+			if i < len(args) {
+				values = append(values, args[i:]...)
+			}
+			*arg.values = values
+			errs.add(arg.checkCount(len(values)))
+			return errs.err()
+		}
+		if i < len(args) {
+			*arg.field = args[i]
+		} else if arg.required {
+			errs.add(errors.Errorf("missing required argument %s", arg.usageName()))
+		}
+	}
+	if len(args) > len(flagSet.positionals) {
+		errs.add(errors.Errorf("too many arguments: expected at most %d, got %d (%s)", len(flagSet.positionals), len(args), strings.Join(args, " ")))
+	}
+	return errs.err()
+}
+
+// checkCount checks the number of values of a variadic argument
+func (arg *positional) checkCount(count int) error {
+	switch {
+	case count < arg.min:
+		return errors.Errorf("expected at least %d %s arguments, got %d", arg.min, arg.name, count)
+	case arg.max > 0 && count > arg.max:
+		return errors.Errorf("expected at most %d %s arguments, got %d", arg.max, arg.name, count)
+	}
+	return nil
+}
+
+// createUsagePositionals returns the positional arguments as shown in the usage line
+func (flagSet *FlagSet) createUsagePositionals() string {
+	var result string
+	for _, arg := range flagSet.positionals {
+		result += " " + arg.usageName()
+	}
+	return result
+}
+
+// writeUsagePositionals writes the descriptions of the positional arguments
+func (flagSet *FlagSet) writeUsagePositionals(writer *tabwriter.Writer) {
+	for _, arg := range flagSet.positionals {
+		fmt.Fprintf(writer, "   %s\t%s\n", arg.name, arg.usage)
+	}
+}
@@ -0,0 +1,93 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionals(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var verbose bool
+	var target, output string
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flagSet.AddPositional(&target, "target", "Target to scan", true)
+	flagSet.AddPositional(&output, "output", "Output file", false)
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), " [flags] <target> [output]\n")
+	require.Contains(t, usage.String(), "Arguments:\n   target  Target to scan\n   output  Output file\n")
+
+	require.Nil(t, flagSet.ParseArgs([]string{"-verbose", "example.com"}), "could not parse positional arguments")
+	require.Equal(t, "example.com", target)
+	require.Empty(t, output)
+
+	require.Nil(t, flagSet.ParseArgs([]string{"example.com", "out.txt"}), "could not parse positional arguments")
+	require.Equal(t, "out.txt", output)
+
+	err := flagSet.ParseArgs(nil)
+	require.NotNil(t, err, "could parse without required argument")
+	require.Equal(t, "missing required argument <target>", err.Error())
+
+	err = flagSet.ParseArgs([]string{"a", "b", "c"})
+	require.NotNil(t, err, "could parse too many arguments")
+	require.Equal(t, "too many arguments: expected at most 2, got 3 (a b c)", err.Error())
+
+	require.Panics(t, func() { flagSet.AddPositional(&target, "extra", "Extra", true) })
+}
+
+func TestVariadicPositional(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var output string
+	var targets []string
+	flagSet.AddPositional(&output, "output", "Output file", true)
+	flagSet.AddVariadicPositional(&targets, "target", "Targets to scan", 1, 3)
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), " [flags] <output> <target>...\n")
+
+	require.Nil(t, flagSet.ParseArgs([]string{"out.txt", "a.com", "b.com"}), "could not parse variadic arguments")
+	require.Equal(t, "out.txt", output)
+	require.Equal(t, []string{"a.com", "b.com"}, targets)
+
+	err := flagSet.ParseArgs([]string{"out.txt"})
+	require.NotNil(t, err, "could parse too few variadic arguments")
+	require.Equal(t, "expected at least 1 target arguments, got 0", err.Error())
+
+	err = flagSet.ParseArgs([]string{"out.txt", "a", "b", "c", "d"})
+	require.NotNil(t, err, "could parse too many variadic arguments")
+	require.Equal(t, "expected at most 3 target arguments, got 4", err.Error())
+
+	require.Panics(t, func() { flagSet.AddPositional(&output, "extra", "Extra", false) })
+}
+
+func TestPositionalsWithRemainder(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var host string
+	var targets []string
+	flagSet.AddPositional(&host, "host", "Host to scan", true)
+
+	require.Nil(t, flagSet.ParseArgs([]string{"example.com", "--", "extra", "-v"}), "could not parse positional arguments with a remainder")
+	require.Equal(t, "example.com", host)
+	require.Equal(t, []string{"extra", "-v"}, flagSet.Remainder())
+
+	err := flagSet.ParseArgs([]string{"--", "example.com"})
+	require.NotNil(t, err, "could bind the remainder to positional arguments")
+	require.Equal(t, "missing required argument <host>", err.Error())
+
+	variadic := NewScopedFlagSet(t.Name()+"-variadic", flag.ContinueOnError)
+	variadic.SetConfigFilePath("")
+	variadic.AddVariadicPositional(&targets, "target", "Targets to scan", 0, 0)
+	require.Nil(t, variadic.ParseArgs([]string{"a.com", "b.com", "--", "c.com"}), "could not parse variadic arguments with a remainder")
+	require.Equal(t, []string{"a.com", "b.com"}, targets)
+	require.Equal(t, []string{"c.com"}, variadic.Remainder())
+}
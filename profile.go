@@ -0,0 +1,81 @@
+package goflags
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// profileFlagName is the name of the built-in flag selecting a config profile
+	profileFlagName = "profile"
+	// profilesConfigKey is the config key holding the profiles of a config file
+	profilesConfigKey = "profiles"
+)
+
+// registerProfileFlag registers the built-in -profile flag
+func (flagSet *FlagSet) registerProfileFlag() {
+	flagSet.registerBuiltinFlag(&flagSet.profile, profileFlagName, "name of the config file profile to use")
+}
+
+// applyProfile returns the config data with the values of the selected
+// profile taking precedence over the top level ones, and the profile values.
+func (flagSet *FlagSet) applyProfile(data map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	if flagSet.profile == "" {
+		return data, nil
+	}
+	profile, ok := configMap(configMapValue(data[profilesConfigKey], flagSet.profile))
+	if !ok {
+		return data, nil
+	}
+	flagSet.profileFound = true
+
+	merged := make(map[string]interface{}, len(data)+len(profile))
+	for key, value := range data {
+		merged[key] = value
+	}
+	for key, value := range profile {
+		merged[key] = value
+	}
+	return merged, profile
+}
+
+// checkProfile returns an error if the selected profile was not found in any config file
+func (flagSet *FlagSet) checkProfile() error {
+	if flagSet.profile != "" && !flagSet.profileFound {
+		return errors.Errorf("profile %q not found in config files", flagSet.profile)
+	}
+	return nil
+}
+
+// configMapValue returns the value of a key in a decoded config map
+func configMapValue(value interface{}, key string) interface{} {
+	values, ok := configMap(value)
+	if !ok {
+		return nil
+	}
+	return values[key]
+}
+
+// configMap converts the maps decoded from the different config formats
+func configMap(value interface{}) (map[string]interface{}, bool) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return value, true
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			converted[fmt.Sprint(key)] = item
+		}
+		return converted, true
+	case []map[string]interface{}: // HCL blocks
+		converted := make(map[string]interface{})
+		for _, block := range value {
+			for key, item := range block {
+				converted[key] = item
+			}
+		}
+		return converted, len(value) > 0
+	}
+	return nil, false
+}
@@ -0,0 +1,65 @@
+package goflags
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigProfiles(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configs := map[string]string{
+		"config.yaml": "threads: 10\nrate-limit: 100\nprofiles:\n  fast:\n    threads: 50\n    rate-limit: 500\n  thorough:\n    threads: 5",
+		"config.hcl":  "threads = 10\nrate-limit = 100\nprofiles {\n  fast {\n    threads = 50\n    rate-limit = 500\n  }\n}",
+	}
+	for name, content := range configs {
+		t.Run(name, func(t *testing.T) {
+			configFile := filepath.Join(tempDir, name)
+			err := ioutil.WriteFile(configFile, []byte(content), os.ModePerm)
+			require.Nil(t, err, "could not write config")
+
+			tearDown(t.Name())
+			flagSet := NewFlagSet()
+			var threads, rateLimit int
+			flagSet.IntVar(&threads, "threads", 1, "Threads")
+			flagSet.IntVar(&rateLimit, "rate-limit", 1, "Rate limit")
+			flagSet.registerProfileFlag()
+
+			err = flag.CommandLine.Parse([]string{"-profile", "fast", "-rate-limit", "200"})
+			require.Nil(t, err, "could not parse flags")
+			err = flagSet.MergeConfigFile(configFile)
+			require.Nil(t, err, "could not merge config")
+			require.Nil(t, flagSet.checkProfile(), "could not find profile")
+
+			require.Equal(t, 50, threads, "could not use profile value")
+			require.Equal(t, 200, rateLimit, "could not prefer cli value")
+
+			tearDown(t.Name())
+		})
+	}
+}
+
+func TestConfigProfileNotFound(t *testing.T) {
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var threads int
+	flagSet.IntVar(&threads, "threads", 1, "Threads")
+	flagSet.registerProfileFlag()
+
+	err := flag.CommandLine.Parse([]string{"-profile", "missing"})
+	require.Nil(t, err, "could not parse flags")
+	err = flagSet.mergeConfigData(map[string]interface{}{"threads": 10}, "config.yaml", nil)
+	require.Nil(t, err, "could not merge config data")
+
+	require.Equal(t, 10, threads)
+	require.NotNil(t, flagSet.checkProfile(), "could find missing profile")
+
+	tearDown(t.Name())
+}
@@ -0,0 +1,28 @@
+package goflags
+
+import "strings"
+
+// registryRoots are the names of the registry root keys accepted by NewRegistrySource
+var registryRoots = map[string]string{
+	"HKCU":                "HKCU",
+	"HKEY_CURRENT_USER":   "HKCU",
+	"HKLM":                "HKLM",
+	"HKEY_LOCAL_MACHINE":  "HKLM",
+	"HKCR":                "HKCR",
+	"HKEY_CLASSES_ROOT":   "HKCR",
+	"HKU":                 "HKU",
+	"HKEY_USERS":          "HKU",
+	"HKCC":                "HKCC",
+	"HKEY_CURRENT_CONFIG": "HKCC",
+}
+
+// splitRegistryPath splits a registry key path like HKCU\Software\app into
+// its root key abbreviation and its subkey path.
+func splitRegistryPath(path string) (root, subKey string, ok bool) {
+	parts := strings.SplitN(strings.Replace(path, "/", `\`, -1), `\`, 2)
+	root, ok = registryRoots[strings.ToUpper(parts[0])]
+	if len(parts) == 2 {
+		subKey = strings.Trim(parts[1], `\`)
+	}
+	return root, subKey, ok
+}
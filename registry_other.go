@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package goflags
+
+import "github.com/pkg/errors"
+
+// NewRegistrySource returns a value source reading flag values from the values of a
+// registry key, which is only supported on Windows.
+func NewRegistrySource(path string) (ValueSource, error) {
+	if _, _, ok := splitRegistryPath(path); !ok {
+		return nil, errors.Errorf("invalid registry key %q", path)
+	}
+	return nil, errors.New("registry sources are only supported on windows")
+}
@@ -0,0 +1,22 @@
+package goflags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRegistryPath(t *testing.T) {
+	root, subKey, ok := splitRegistryPath(`HKEY_CURRENT_USER\Software\tool\`)
+	require.True(t, ok)
+	require.Equal(t, "HKCU", root)
+	require.Equal(t, `Software\tool`, subKey)
+
+	root, subKey, ok = splitRegistryPath("hklm/Software/Policies/tool")
+	require.True(t, ok)
+	require.Equal(t, "HKLM", root)
+	require.Equal(t, `Software\Policies\tool`, subKey)
+
+	_, _, ok = splitRegistryPath(`HKXX\Software`)
+	require.False(t, ok, "could split invalid root key")
+}
@@ -0,0 +1,67 @@
+//go:build windows
+// +build windows
+
+package goflags
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/windows/registry"
+)
+
+// registrySource is a value source reading the values of a registry key
+type registrySource struct {
+	path   string
+	root   registry.Key
+	subKey string
+}
+
+// NewRegistrySource returns a value source reading flag values from the values of a
+// registry key like HKCU\Software\<app> or HKLM\Software\Policies\<app>, where Group
+// Policy pushes configuration. String, integer and multi-string values are supported,
+// the items of multi-string values being joined with commas.
+func NewRegistrySource(path string) (ValueSource, error) {
+	root, subKey, ok := splitRegistryPath(path)
+	if !ok {
+		return nil, errors.Errorf("invalid registry key %q", path)
+	}
+	roots := map[string]registry.Key{
+		"HKCU": registry.CURRENT_USER,
+		"HKLM": registry.LOCAL_MACHINE,
+		"HKCR": registry.CLASSES_ROOT,
+		"HKU":  registry.USERS,
+		"HKCC": registry.CURRENT_CONFIG,
+	}
+	return &registrySource{path: path, root: roots[root], subKey: subKey}, nil
+}
+
+func (source *registrySource) Name() string {
+	return source.path
+}
+
+func (source *registrySource) Lookup(flagName string) (string, bool) {
+	key, err := registry.OpenKey(source.root, source.subKey, registry.QUERY_VALUE)
+	if err != nil {
+		return "", false
+	}
+	defer key.Close()
+
+	_, valueType, err := key.GetValue(flagName, nil)
+	if err != nil {
+		return "", false
+	}
+	switch valueType {
+	case registry.SZ, registry.EXPAND_SZ:
+		value, _, err := key.GetStringValue(flagName)
+		return value, err == nil
+	case registry.DWORD, registry.QWORD:
+		value, _, err := key.GetIntegerValue(flagName)
+		return strconv.FormatUint(value, 10), err == nil
+	case registry.MULTI_SZ:
+		values, _, err := key.GetStringsValue(flagName)
+		return strings.Join(values, ","), err == nil
+	}
+	return "", false
+}
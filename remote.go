@@ -0,0 +1,151 @@
+package goflags
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultRemoteConfigTimeout is the default timeout of remote config requests
+const defaultRemoteConfigTimeout = 10 * time.Second
+
+// RemoteConfigOptions configures how a remote config file is fetched
+type RemoteConfigOptions struct {
+	// Timeout is the timeout of the request, 10 seconds by default
+	Timeout time.Duration
+	// Headers are added to the request, for example an Authorization header
+	Headers map[string]string
+	// CacheDir is the directory caching fetched config files,
+	// <user cache dir>/<app>/config by default
+	CacheDir string
+}
+
+// remoteConfigMeta holds the validators of a cached remote config file
+type remoteConfigMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last-modified,omitempty"`
+}
+
+// MergeConfigURL fetches a config file from an HTTP(S) URL and merges it into the flags.
+//
+// Fetched files are cached and revalidated with ETag and If-Modified-Since,
+// the cached copy being used when the URL can't be reached.
+func (flagSet *FlagSet) MergeConfigURL(configURL string, options *RemoteConfigOptions) error {
+	if options == nil {
+		options = &RemoteConfigOptions{}
+	}
+	parsed, err := url.Parse(configURL)
+	if err != nil {
+		return errors.Wrap(err, "invalid config url")
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return errors.Errorf("unsupported config url scheme %q", parsed.Scheme)
+	}
+
+	content, err := flagSet.fetchConfigURL(configURL, options)
+	if err != nil {
+		return err
+	}
+	return flagSet.mergeConfigContent(content, configURL, path.Ext(parsed.Path), false)
+}
+
+// fetchConfigURL returns the content of the remote config file, using the cached
+// copy when it has not been modified or the request fails.
+func (flagSet *FlagSet) fetchConfigURL(configURL string, options *RemoteConfigOptions) ([]byte, error) {
+	cacheFile, err := remoteConfigCacheFile(configURL, options.CacheDir, flagSet.appName())
+	if err != nil {
+		return nil, err
+	}
+	cached, cacheErr := ioutil.ReadFile(cacheFile)
+	var meta remoteConfigMeta
+	if cacheErr == nil {
+		if metaContent, err := ioutil.ReadFile(cacheFile + ".json"); err == nil {
+			_ = json.Unmarshal(metaContent, &meta)
+		}
+	}
+
+	request, err := newRemoteConfigRequest(http.MethodGet, configURL, nil, options)
+	if err != nil {
+		return nil, err
+	}
+	if meta.ETag != "" {
+		request.Header.Set("If-None-Match", meta.ETag)
+	}
+	if meta.LastModified != "" {
+		request.Header.Set("If-Modified-Since", meta.LastModified)
+	}
+
+	response, err := remoteConfigClient(options).Do(request)
+	if err != nil {
+		if cacheErr == nil {
+			return cached, nil
+		}
+		return nil, errors.Wrap(err, "could not fetch config url")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotModified && cacheErr == nil {
+		return cached, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("could not fetch config url: unexpected status %s", response.Status)
+	}
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read config url")
+	}
+
+	// caching is best effort, the fetched content being used regardless
+	meta = remoteConfigMeta{ETag: response.Header.Get("ETag"), LastModified: response.Header.Get("Last-Modified")}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), flagSet.configDirMode()); err == nil {
+		if err := flagSet.saveConfigFile(cacheFile, content, false); err == nil {
+			metaContent, _ := json.Marshal(meta)
+			_ = flagSet.saveConfigFile(cacheFile+".json", metaContent, false)
+		}
+	}
+	return content, nil
+}
+
+// newRemoteConfigRequest creates a request to a remote config source with the headers of the options
+func newRemoteConfigRequest(method, requestURL string, body io.Reader, options *RemoteConfigOptions) (*http.Request, error) {
+	request, err := http.NewRequest(method, requestURL, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create config request")
+	}
+	for name, value := range options.Headers {
+		request.Header.Set(name, value)
+	}
+	return request, nil
+}
+
+// remoteConfigClient returns the HTTP client used to reach remote config sources
+func remoteConfigClient(options *RemoteConfigOptions) *http.Client {
+	timeout := options.Timeout
+	if timeout == 0 {
+		timeout = defaultRemoteConfigTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// remoteConfigCacheFile returns the path caching the config file fetched from configURL
+func remoteConfigCacheFile(configURL, cacheDir, appName string) (string, error) {
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", errors.Wrap(err, "could not get cache directory")
+		}
+		cacheDir = filepath.Join(userCacheDir, appName, "config")
+	}
+	hash := sha256.Sum256([]byte(configURL))
+	return filepath.Join(cacheDir, hex.EncodeToString(hash[:])), nil
+}
@@ -0,0 +1,60 @@
+package goflags
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeConfigURL(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(cacheDir)
+
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("threads: 10"))
+	}))
+	options := &RemoteConfigOptions{Headers: map[string]string{"Authorization": "Bearer token"}, CacheDir: cacheDir}
+
+	for i := 0; i < 2; i++ {
+		tearDown(t.Name())
+		flagSet := NewFlagSet()
+		var threads int
+		flagSet.IntVar(&threads, "threads", 1, "Threads")
+		err = flagSet.MergeConfigURL(server.URL+"/config.yaml", options)
+		require.Nil(t, err, "could not merge config url")
+		require.Equal(t, 10, threads)
+	}
+	require.Equal(t, 2, requests)
+	require.Equal(t, 1, notModified, "could not revalidate cached config")
+
+	err = NewFlagSet().MergeConfigURL(server.URL+"/config.yaml", &RemoteConfigOptions{CacheDir: cacheDir})
+	require.NotNil(t, err, "could fetch config without authorization")
+
+	server.Close()
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var threads int
+	flagSet.IntVar(&threads, "threads", 1, "Threads")
+	err = flagSet.MergeConfigURL(server.URL+"/config.yaml", options)
+	require.Nil(t, err, "could not use cached config")
+	require.Equal(t, 10, threads)
+
+	tearDown(t.Name())
+}
@@ -0,0 +1,219 @@
+package goflags
+
+import (
+	"flag"
+	"reflect"
+	"sync"
+)
+
+// registeredValue is the value of a flag when it was registered, restored by Reset
+type registeredValue struct {
+	value        flag.Value // copy of the value, nil if its type can't be copied
+	text         string
+	defaultValue interface{}
+}
+
+// Reset restores the flags and positional arguments to the values they had when
+// they were registered and forgets the state of the previous Parse, so that the
+// FlagSet can parse another set of arguments. The flags of the commands are reset too.
+//
+// NOTE: Reset replaces flag.CommandLine for a FlagSet created with NewFlagSet.
+func (flagSet *FlagSet) Reset() {
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		currentFlag := flagSet.CommandLine().Lookup(key)
+		if key != data.name() || currentFlag == nil {
+			return
+		}
+		_ = restoreValue(unwrapValue(currentFlag.Value), data.registered)
+		data.defaultValue = data.registered.defaultValue
+	})
+	for _, arg := range flagSet.positionals {
+		if arg.variadic {
+			*arg.values = nil
+		} else {
+			*arg.field = arg.initial
+		}
+	}
+
+	// a new flag.FlagSet forgets the flags set by the previous Parse
+	previous := flagSet.CommandLine()
+	commandLine := flag.NewFlagSet(previous.Name(), previous.ErrorHandling())
+	commandLine.SetOutput(previous.Output())
+	commandLine.Usage = previous.Usage
+	previous.VisitAll(func(fl *flag.Flag) {
+		if wrapped, ok := fl.Value.(*flagValue); ok {
+			wrapped.inputs = nil
+		}
+		commandLine.Var(fl.Value, fl.Name, fl.Usage)
+	})
+	if flagSet.commandLine != nil {
+		flagSet.commandLine = commandLine
+	} else {
+		flag.CommandLine = commandLine
+	}
+
+	flagSet.flagSources = nil
+	flagSet.unknownFlags = nil
+	flagSet.remainder = nil
+	flagSet.profileFound = false
+	flagSet.configSections = nil
+	flagSet.invokedCommand = nil
+	flagSet.frozen = false
+	for _, command := range flagSet.commands {
+		command.Reset()
+	}
+}
+
+// Clone returns a copy of the FlagSet definition owning its flag.FlagSet, with the
+// flags and positional arguments at their registered values. The values parsed by
+// the copy are read with the Get methods, Lookup or Arg as they are not stored in
+// the fields the flags were registered with, except for Var flags of custom types
+// which can't be copied.
+//
+// NOTE: the commands of the FlagSet are not copied.
+func (flagSet *FlagSet) Clone() *FlagSet {
+	clone := *flagSet
+	clone.flagKeys = *newInsertionOrderedMap()
+	clone.commandLine = flag.NewFlagSet(flagSet.CommandLine().Name(), flagSet.CommandLine().ErrorHandling())
+	clone.commandLine.SetOutput(flagSet.CommandLine().Output())
+	clone.explicitConfigFile = ""
+	clone.writeConfigFile = ""
+	clone.profile = ""
+	clone.showVersion = false
+	clone.registrationErrs = append(Errors(nil), flagSet.registrationErrs...)
+	clone.configSearchPaths = append([]string(nil), flagSet.configSearchPaths...)
+	clone.dotEnvFiles = append([]string(nil), flagSet.dotEnvFiles...)
+	clone.valueSources = append([]prioritizedSource(nil), flagSet.valueSources...)
+	clone.requiredTogether = append([][]string(nil), flagSet.requiredTogether...)
+	clone.oneRequired = append([][]string(nil), flagSet.oneRequired...)
+	clone.requirements = append([]conditionalRequirement(nil), flagSet.requirements...)
+	clone.aliases = append([]string(nil), flagSet.aliases...)
+	clone.groups = append([]flagGroup(nil), flagSet.groups...)
+	clone.examples = append([]example(nil), flagSet.examples...)
+	clone.configMigrations = make(map[int]ConfigMigration, len(flagSet.configMigrations))
+	for version, migration := range flagSet.configMigrations {
+		clone.configMigrations[version] = migration
+	}
+	clone.valueResolvers = make(map[string]ValueResolver, len(flagSet.valueResolvers))
+	for scheme, resolver := range flagSet.valueResolvers {
+		clone.valueResolvers[scheme] = resolver
+	}
+	clone.flagSources = nil
+	clone.valuesMutex = &sync.RWMutex{}
+	clone.unknownFlags = nil
+	clone.remainder = nil
+	clone.profileFound = false
+	clone.configSections = nil
+	clone.commands = nil
+	clone.invokedCommand = nil
+	clone.inherited = nil
+	clone.defaultCommand = ""
+	clone.frozen = false
+
+	values := make(map[*FlagData]*flagValue)
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		currentFlag := flagSet.CommandLine().Lookup(key)
+		if currentFlag == nil || isBuiltinFlag(key) {
+			return // registered again by Parse, bound to the copy
+		}
+		wrapped, ok := values[data]
+		if !ok {
+			copied := *data
+			copied.flagSet = &clone
+			copied.defaultValue = data.registered.defaultValue
+			copied.envNames = append([]string(nil), data.envNames...)
+			copied.related = append([]string(nil), data.related...)
+			copied.validators = append([]func(value interface{}) error(nil), data.validators...)
+
+			value := copyValue(data.registered.value)
+			if value == nil {
+				value = unwrapValue(currentFlag.Value)
+			}
+			wrapped = &flagValue{Value: value, flagSet: &clone, data: &copied}
+			values[data] = wrapped
+		}
+		clone.commandLine.Var(wrapped, key, currentFlag.Usage)
+		clone.flagKeys.Set(key, wrapped.data)
+	})
+
+	clone.positionals = make([]*positional, 0, len(flagSet.positionals))
+	for _, arg := range flagSet.positionals {
+		copied := *arg
+		if copied.variadic {
+			copied.values = new([]string)
+		} else {
+			copied.field = new(string)
+			*copied.field = arg.initial
+		}
+		clone.positionals = append(clone.positionals, &copied)
+	}
+	return &clone
+}
+
+// isBuiltinFlag returns true for the flags registered by Parse
+func isBuiltinFlag(name string) bool {
+	return name == configFlagName || name == writeConfigFlagName || name == profileFlagName || name == versionFlagName
+}
+
+// copyValue returns a flag.Value of the same type holding a copy of the value,
+// or nil if the type of the value is unknown.
+func copyValue(value flag.Value) flag.Value {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case *enumValue:
+		field := *value.field
+		return &enumValue{field: &field, options: value.options}
+	case *enumSliceValue:
+		field := append([]string(nil), *value.field...)
+		return &enumSliceValue{field: &field, options: value.options}
+	case *multiChoiceValue:
+		copied := *value
+		field := append([]string(nil), *value.field...)
+		copied.field = &field
+		return &copied
+	case *hexBytesValue:
+		copied := *value
+		field := append([]byte(nil), *value.field...)
+		copied.field = &field
+		return &copied
+	}
+
+	// the values of the flag package and StringSlice point to a basic type
+	pointer := reflect.ValueOf(value)
+	if pointer.Kind() != reflect.Ptr || pointer.IsNil() || pointer.Elem().Kind() == reflect.Struct {
+		return nil
+	}
+	copied := reflect.New(pointer.Elem().Type())
+	copied.Elem().Set(copyElem(pointer.Elem()))
+	return copied.Interface().(flag.Value)
+}
+
+// restoreValue sets the value of a flag back to its registered value
+func restoreValue(value flag.Value, registered registeredValue) error {
+	if registered.value == nil {
+		return replaceValue(value, registered.text)
+	}
+	switch value := value.(type) {
+	case *enumValue:
+		*value.field = *registered.value.(*enumValue).field
+	case *enumSliceValue:
+		*value.field = append([]string(nil), *registered.value.(*enumSliceValue).field...)
+	case *multiChoiceValue:
+		*value.field = append([]string(nil), *registered.value.(*multiChoiceValue).field...)
+		value.selected = false
+	case *hexBytesValue:
+		*value.field = append([]byte(nil), *registered.value.(*hexBytesValue).field...)
+	default:
+		reflect.ValueOf(value).Elem().Set(copyElem(reflect.ValueOf(registered.value).Elem()))
+	}
+	return nil
+}
+
+// copyElem returns a copy of a slice, or the value itself for other types
+func copyElem(value reflect.Value) reflect.Value {
+	if value.Kind() != reflect.Slice || value.IsNil() {
+		return value
+	}
+	return reflect.AppendSlice(reflect.MakeSlice(value.Type(), 0, value.Len()), value)
+}
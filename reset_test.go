@@ -0,0 +1,80 @@
+package goflags
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReset(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var output, format, target string
+	var threads int
+	var tags StringSlice
+	flagSet.StringVarP(&output, "output", "o", "", "Output file")
+	flagSet.EnumVar(&format, "format", "json", []string{"json", "yaml"}, "Output format")
+	flagSet.IntVar(&threads, "threads", 10, "Threads")
+	flagSet.StringSliceVar(&tags, "tags", []string{"cve"}, "Tags")
+	flagSet.AddPositional(&target, "target", "Target", false)
+	flagSet.SingleOccurrence = true
+
+	err := flagSet.ParseArgs([]string{"-o", "out.txt", "-format", "yaml", "-threads", "20", "-tags", "xss", "example.com"})
+	require.Nil(t, err, "could not parse flags")
+	require.Equal(t, []string{"cve", "xss"}, []string(tags))
+	require.True(t, flagSet.Changed("output"))
+
+	flagSet.Reset()
+	require.Equal(t, "", output)
+	require.Equal(t, "json", format)
+	require.Equal(t, 10, threads)
+	require.Equal(t, []string{"cve"}, []string(tags))
+	require.Equal(t, "", target)
+	require.False(t, flagSet.Changed("output"))
+	require.Equal(t, 0, flagSet.NArg())
+
+	err = flagSet.ParseArgs([]string{"-o", "other.txt"})
+	require.Nil(t, err, "could not parse flags after reset")
+	require.Equal(t, "other.txt", output)
+	require.Equal(t, 10, threads)
+	require.Equal(t, "", target)
+}
+
+func TestClone(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var output, target string
+	var threads int
+	var tags StringSlice
+	flagSet.StringVarP(&output, "output", "o", "", "Output file")
+	flagSet.IntVar(&threads, "threads", 10, "Threads")
+	flagSet.StringSliceVar(&tags, "tags", []string{"cve"}, "Tags")
+	flagSet.AddPositional(&target, "target", "Target", true)
+
+	clone := flagSet.Clone()
+	err := clone.ParseArgs([]string{"-o", "out.txt", "-threads", "20", "-tags", "xss", "example.com"})
+	require.Nil(t, err, "could not parse cloned flags")
+
+	stringValue, err := clone.GetString("o")
+	require.Nil(t, err)
+	require.Equal(t, "out.txt", stringValue)
+	intValue, err := clone.GetInt("threads")
+	require.Nil(t, err)
+	require.Equal(t, 20, intValue)
+	sliceValue, err := clone.GetStringSlice("tags")
+	require.Nil(t, err)
+	require.Equal(t, []string{"cve", "xss"}, sliceValue)
+	require.Equal(t, "example.com", clone.Arg(0))
+
+	require.Equal(t, "", output, "original flag was modified by the clone")
+	require.Equal(t, 10, threads)
+	require.Equal(t, []string{"cve"}, []string(tags))
+	require.Equal(t, "", target)
+
+	err = flagSet.ParseArgs([]string{"-threads", "30"})
+	require.EqualError(t, err, "missing required argument <target>")
+	intValue, err = clone.GetInt("threads")
+	require.Nil(t, err)
+	require.Equal(t, 20, intValue)
+}
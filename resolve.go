@@ -0,0 +1,60 @@
+package goflags
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ValueResolver returns the value a reference points to, like the
+// secret/data/app#token part of a vault:secret/data/app#token value.
+type ValueResolver func(reference string) (string, error)
+
+// AddValueResolver makes Parse replace the values set from the environment, config
+// files or value sources which start with the scheme followed by a colon with the value
+// returned by the resolver for the rest of the value.
+func (flagSet *FlagSet) AddValueResolver(scheme string, resolver ValueResolver) {
+	if flagSet.valueResolvers == nil {
+		flagSet.valueResolvers = make(map[string]ValueResolver)
+	}
+	flagSet.valueResolvers[scheme] = resolver
+}
+
+// resolveValues replaces the references in the values of non-slice flags
+// set from the environment, config files or value sources.
+func (flagSet *FlagSet) resolveValues() error {
+	if len(flagSet.valueResolvers) == 0 {
+		return nil
+	}
+	var err error
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if err != nil || key != data.name() {
+			return
+		}
+		if kind := flagSet.Source(key).Kind; kind != SourceEnv && kind != SourceConfigFile && kind != SourceCustom {
+			return
+		}
+		currentFlag := flagSet.CommandLine().Lookup(key)
+		if currentFlag == nil || isSliceValue(unwrapValue(currentFlag.Value)) {
+			return
+		}
+		value := currentFlag.Value.String()
+		separator := strings.Index(value, ":")
+		if separator <= 0 {
+			return
+		}
+		resolver, ok := flagSet.valueResolvers[value[:separator]]
+		if !ok {
+			return
+		}
+		resolved, resolveErr := resolver(value[separator+1:])
+		if resolveErr != nil {
+			err = errors.Wrapf(resolveErr, "could not resolve -%s", key)
+			return
+		}
+		if setErr := unwrapValue(currentFlag.Value).Set(resolved); setErr != nil {
+			err = errors.Wrapf(setErr, "invalid value resolved for -%s", key)
+		}
+	})
+	return err
+}
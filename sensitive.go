@@ -0,0 +1,11 @@
+package goflags
+
+// redactedValue replaces the values of sensitive flags in any output
+const redactedValue = "[REDACTED]"
+
+// Sensitive marks the flag value as sensitive, masking it in the usage output
+// and value dumps, and leaving it out of the generated config file.
+func (flagData *FlagData) Sensitive() *FlagData {
+	flagData.sensitive = true
+	return flagData
+}
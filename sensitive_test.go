@@ -0,0 +1,35 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensitiveFlags(t *testing.T) {
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+
+	var token, output string
+	flagSet.StringVarP(&token, "token", "t", "secret-token", "API token").Sensitive()
+	flagSet.StringVar(&output, "output", "out.txt", "Output file")
+
+	config := string(flagSet.generateDefaultConfig())
+	require.NotContains(t, config, "secret-token", "could not leave out sensitive value")
+	require.Contains(t, config, "#token: \n")
+	require.Contains(t, config, "#output: out.txt")
+
+	flagSet.Marshal = true
+	config = string(flagSet.generateDefaultConfig())
+	require.NotContains(t, config, "secret-token", "could not leave out sensitive value")
+
+	usage := &bytes.Buffer{}
+	flag.CommandLine.SetOutput(usage)
+	flagSet.usageFunc()
+	require.NotContains(t, usage.String(), "secret-token", "could not mask sensitive value")
+	require.Contains(t, usage.String(), redactedValue)
+
+	tearDown(t.Name())
+}
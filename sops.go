@@ -0,0 +1,321 @@
+package goflags
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// sopsMetadataKey is the key of the metadata of the config files encrypted with sops
+const sopsMetadataKey = "sops"
+
+// sopsDefaultUnencryptedSuffix is the suffix of the keys left unencrypted by sops when no rule is set
+const sopsDefaultUnencryptedSuffix = "_unencrypted"
+
+// sopsMACOnlyEncryptedInitialization starts the MAC of sops files authenticating only their encrypted values
+var sopsMACOnlyEncryptedInitialization = []byte{0x8a, 0x3f, 0xd2, 0xad, 0x54, 0xce, 0x66, 0x52, 0x7b, 0x10, 0x34, 0xf3, 0xd1, 0x47, 0xbe, 0xb, 0xb, 0x97, 0x5b, 0x3b, 0xf4, 0x4f, 0x72, 0xc6, 0xfd, 0xad, 0xec, 0x81, 0x76, 0xf2, 0x7d, 0x69}
+
+// sopsValueRegex matches the values encrypted by sops
+var sopsValueRegex = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]`)
+
+// sopsMetadata is the metadata of a sops file needed to decrypt it with age
+type sopsMetadata struct {
+	Age []struct {
+		Recipient string `yaml:"recipient"`
+		Enc       string `yaml:"enc"`
+	} `yaml:"age"`
+	KeyGroups               []interface{} `yaml:"key_groups"`
+	LastModified            string        `yaml:"lastmodified"`
+	MAC                     string        `yaml:"mac"`
+	UnencryptedSuffix       string        `yaml:"unencrypted_suffix"`
+	EncryptedSuffix         string        `yaml:"encrypted_suffix"`
+	UnencryptedRegex        string        `yaml:"unencrypted_regex"`
+	EncryptedRegex          string        `yaml:"encrypted_regex"`
+	UnencryptedCommentRegex string        `yaml:"unencrypted_comment_regex"`
+	EncryptedCommentRegex   string        `yaml:"encrypted_comment_regex"`
+	MACOnlyEncrypted        bool          `yaml:"mac_only_encrypted"`
+}
+
+// sopsDecrypter decrypts the values of a sops file, computing its MAC
+type sopsDecrypter struct {
+	metadata sopsMetadata
+	dataKey  []byte
+	mac      hash.Hash
+}
+
+// decryptSopsConfig decrypts a YAML or JSON config file encrypted by sops for age
+// recipients with the config key, returning the plaintext YAML without the sops
+// metadata. It reports false for files which are not encrypted by sops.
+//
+// Only age is supported among the key management services of sops, and comments
+// encrypted with the comment regex rules are not.
+func (flagSet *FlagSet) decryptSopsConfig(content []byte) ([]byte, bool, error) {
+	if !bytes.Contains(content, []byte(sopsMetadataKey)) {
+		return nil, false, nil
+	}
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &root); err != nil || len(root.Content) == 0 || root.Content[0].Kind != yamlv3.MappingNode {
+		return nil, false, nil // reported when decoding the config
+	}
+	document := root.Content[0]
+	metadataIndex := -1
+	for i := 0; i+1 < len(document.Content); i += 2 {
+		if document.Content[i].Value == sopsMetadataKey && document.Content[i+1].Kind == yamlv3.MappingNode {
+			metadataIndex = i
+		}
+	}
+	if metadataIndex < 0 {
+		return nil, false, nil
+	}
+	var metadata sopsMetadata
+	if err := document.Content[metadataIndex+1].Decode(&metadata); err != nil || metadata.MAC == "" {
+		return nil, false, nil
+	}
+	document.Content = append(document.Content[:metadataIndex], document.Content[metadataIndex+2:]...)
+
+	switch {
+	case len(metadata.KeyGroups) > 0:
+		return nil, true, errors.New("key groups are not supported")
+	case metadata.UnencryptedCommentRegex != "" || metadata.EncryptedCommentRegex != "":
+		return nil, true, errors.New("comment regex rules are not supported")
+	case metadata.UnencryptedSuffix == "" && metadata.EncryptedSuffix == "" && metadata.UnencryptedRegex == "" && metadata.EncryptedRegex == "":
+		metadata.UnencryptedSuffix = sopsDefaultUnencryptedSuffix
+	}
+	dataKey, err := flagSet.sopsDataKey(metadata)
+	if err != nil {
+		return nil, true, err
+	}
+
+	decrypter := &sopsDecrypter{metadata: metadata, dataKey: dataKey, mac: sha512.New()}
+	if metadata.MACOnlyEncrypted {
+		decrypter.mac.Write(sopsMACOnlyEncryptedInitialization)
+	}
+	if err := decrypter.decryptNode(document, nil); err != nil {
+		return nil, true, err
+	}
+	if err := decrypter.verifyMAC(); err != nil {
+		return nil, true, err
+	}
+	plaintext, err := yamlv3.Marshal(&root)
+	return plaintext, true, err
+}
+
+// sopsDataKey decrypts the data key of a sops file with the first age recipient the config key matches
+func (flagSet *FlagSet) sopsDataKey(metadata sopsMetadata) ([]byte, error) {
+	if len(metadata.Age) == 0 {
+		return nil, errors.New("no age recipients, only age is supported")
+	}
+	var lastErr error
+	for _, recipient := range metadata.Age {
+		dataKey, err := flagSet.decryptConfig([]byte(recipient.Enc))
+		if err == nil {
+			return dataKey, nil
+		}
+		lastErr = err
+	}
+	return nil, errors.Wrap(lastErr, "could not decrypt data key")
+}
+
+// decryptNode decrypts the scalar values of a node in place, in the order sops authenticates them
+func (decrypter *sopsDecrypter) decryptNode(node *yamlv3.Node, path []string) error {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := decrypter.decryptNode(node.Content[i+1], append(path[:len(path):len(path)], node.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+	case yamlv3.SequenceNode:
+		for _, item := range node.Content {
+			if err := decrypter.decryptNode(item, path); err != nil {
+				return err
+			}
+		}
+	case yamlv3.AliasNode:
+		return errors.Errorf("alias of %s is not supported", strings.Join(path, "."))
+	case yamlv3.ScalarNode:
+		return decrypter.decryptScalar(node, path)
+	}
+	return nil
+}
+
+// decryptScalar decrypts a scalar value if it is encrypted and adds it to the MAC
+func (decrypter *sopsDecrypter) decryptScalar(node *yamlv3.Node, path []string) error {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+	if value == nil {
+		return nil
+	}
+	encrypted := decrypter.shouldBeEncrypted(path)
+	if encrypted {
+		ciphertext, ok := value.(string)
+		if !ok {
+			return errors.Errorf("value of %s is not encrypted", strings.Join(path, "."))
+		}
+		plaintext, err := decrypter.decryptValue(ciphertext, strings.Join(path, ":")+":")
+		if err != nil {
+			return errors.Wrapf(err, "could not decrypt %s", strings.Join(path, "."))
+		}
+		value = plaintext
+		setSopsNodeValue(node, value)
+	}
+	if decrypter.metadata.MACOnlyEncrypted && !encrypted {
+		return nil
+	}
+	authenticated, err := sopsBytes(value)
+	if err != nil {
+		return errors.Wrapf(err, "invalid value of %s", strings.Join(path, "."))
+	}
+	decrypter.mac.Write(authenticated)
+	return nil
+}
+
+// shouldBeEncrypted reports whether sops encrypts the value at the path according to the rules of the file
+func (decrypter *sopsDecrypter) shouldBeEncrypted(path []string) bool {
+	metadata := decrypter.metadata
+	matches := func(match func(key string) bool) bool {
+		for _, key := range path {
+			if match(key) {
+				return true
+			}
+		}
+		return false
+	}
+	matchRegex := func(expression string) func(key string) bool {
+		return func(key string) bool {
+			matched, _ := regexp.MatchString(expression, key)
+			return matched
+		}
+	}
+
+	encrypted := true
+	if metadata.UnencryptedSuffix != "" && matches(func(key string) bool { return strings.HasSuffix(key, metadata.UnencryptedSuffix) }) {
+		encrypted = false
+	}
+	if metadata.EncryptedSuffix != "" {
+		encrypted = matches(func(key string) bool { return strings.HasSuffix(key, metadata.EncryptedSuffix) })
+	}
+	if metadata.UnencryptedRegex != "" && matches(matchRegex(metadata.UnencryptedRegex)) {
+		encrypted = false
+	}
+	if metadata.EncryptedRegex != "" {
+		encrypted = matches(matchRegex(metadata.EncryptedRegex))
+	}
+	return encrypted
+}
+
+// decryptValue decrypts a ENC[AES256_GCM,...] value authenticated with the additional data
+func (decrypter *sopsDecrypter) decryptValue(ciphertext, additionalData string) (interface{}, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+	matches := sopsValueRegex.FindStringSubmatch(ciphertext)
+	if matches == nil {
+		return nil, errors.New("value is not encrypted by sops")
+	}
+	var parts [3][]byte
+	for i := range parts {
+		decoded, err := base64.StdEncoding.DecodeString(matches[i+1])
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid encrypted value")
+		}
+		parts[i] = decoded
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(decrypter.dataKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, err
+	}
+
+	switch valueType := matches[4]; valueType {
+	case "str", "bytes":
+		return string(plaintext), nil
+	case "int":
+		return strconv.Atoi(string(plaintext))
+	case "float":
+		return strconv.ParseFloat(string(plaintext), 64)
+	case "bool":
+		return strconv.ParseBool(string(plaintext))
+	case "time":
+		var value time.Time
+		err := value.UnmarshalText(plaintext)
+		return value, err
+	default:
+		return nil, errors.Errorf("unsupported value type %s", valueType)
+	}
+}
+
+// verifyMAC checks the MAC of the decrypted values against the one of the file
+func (decrypter *sopsDecrypter) verifyMAC() error {
+	lastModified, err := time.Parse(time.RFC3339, decrypter.metadata.LastModified)
+	if err != nil {
+		return errors.Wrap(err, "invalid lastmodified date")
+	}
+	expected, err := decrypter.decryptValue(decrypter.metadata.MAC, lastModified.Format(time.RFC3339))
+	if err != nil {
+		return errors.Wrap(err, "could not decrypt mac")
+	}
+	if expected != fmt.Sprintf("%X", decrypter.mac.Sum(nil)) {
+		return errors.New("mac mismatch, the file was modified without sops")
+	}
+	return nil
+}
+
+// setSopsNodeValue replaces the value of a scalar node with a decrypted value
+func setSopsNodeValue(node *yamlv3.Node, value interface{}) {
+	node.Style = 0
+	switch value := value.(type) {
+	case int:
+		node.Tag, node.Value = "!!int", strconv.Itoa(value)
+	case float64:
+		node.Tag, node.Value = "!!float", strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		node.Tag, node.Value = "!!bool", strconv.FormatBool(value)
+	case time.Time:
+		node.Tag, node.Value = "!!timestamp", value.Format(time.RFC3339Nano)
+	default:
+		node.Tag, node.Value, node.Style = "!!str", fmt.Sprint(value), yamlv3.DoubleQuotedStyle
+	}
+}
+
+// sopsBytes returns a value as authenticated by the MAC of sops
+func sopsBytes(value interface{}) ([]byte, error) {
+	switch value := value.(type) {
+	case string:
+		return []byte(value), nil
+	case int:
+		return []byte(strconv.Itoa(value)), nil
+	case float64:
+		return []byte(strconv.FormatFloat(value, 'f', -1, 64)), nil
+	case bool:
+		if value {
+			return []byte("True"), nil
+		}
+		return []byte("False"), nil
+	case time.Time:
+		return value.MarshalText()
+	}
+	return nil, errors.Errorf("unsupported value of type %T", value)
+}
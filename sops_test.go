@@ -0,0 +1,145 @@
+package goflags
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sopsTestKey is the age identity the sops test files are encrypted for
+const sopsTestKey = "AGE-SECRET-KEY-19L2GX5ELLNWMGXNF4SZ2PTE4F0C8RSGNTUCMGX92MUCCNR6FRCVQ4MV5F9"
+
+// sopsTestYAML is encrypted by sops with the default rules
+const sopsTestYAML = `
+threads: ENC[AES256_GCM,data:CqE=,iv:dxPzkOQt/GK5m+AKfDc3GDyhZOiL3+RzH0yfuC08tmI=,tag:kxuRZ4cmYL11zN+rQy3/6w==,type:int]
+rate: ENC[AES256_GCM,data:0D45,iv:RVUNsvQv+f5+ui853TUY4OSmEafjPux09OZX78cLrOk=,tag:8InxF8aaZlu18anK6vE75g==,type:float]
+verbose: ENC[AES256_GCM,data:zI7C5g==,iv:IV9U3PQB1Ea6SXDCueNyweoNykPEnCgT71DZwj2LLjc=,tag:pBUo6lH6RjcfZUZh/Zrk7w==,type:bool]
+token: ENC[AES256_GCM,data:AsHlYbXW5OQEU/Ht,iv:BHY4n1mWSuGW5wI2MstNKg24D4eoH2Hz9iaxxeUQAjY=,tag:evLkzzGjbeuazAr/quKG9g==,type:str]
+targets:
+    - ENC[AES256_GCM,data:OKI2nA8=,iv:AKqjtiofLSM20CaudKsaIQznzH1wVZ7QzQgeRtewqz0=,tag:ChROJEIaUl6PSQPUxGVQSA==,type:str]
+    - ENC[AES256_GCM,data:0pZanqA=,iv:bpCdVSuLIMM29SWf40KKBMlIbX/+wioPYNPkDbnz+Hg=,tag:nGw0ksmXcpXZLt2w/HXEgQ==,type:str]
+scan:
+    retries: ENC[AES256_GCM,data:Iw==,iv:5JJnkbz7v/RAQcBIeQ7BpGuf4y8lH1RlTpLAym9VrAg=,tag:VGLdmrAO1gQEKBD9THObng==,type:int]
+port_unencrypted: 8080
+sops:
+    age:
+        - enc: |
+            -----BEGIN AGE ENCRYPTED FILE-----
+            YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBFODd6dU5aU3ZaK3dzNzY0
+            VVBTTWpsRXZoQzFaa3FxT0F6T25XVklHQXdRClpLdm1ET2E5NHBjMktSdE1QREQ5
+            OGhXclc1Slhva2dzYVZpVUgzVXhBblkKLS0tIFpQRkpSNDVDbG5sditIcnVzQ3Nq
+            VjcyYTY4RXRsUVh4cXc4Zkw0eHRvMGsK/B3K2NXo5S4AczYDMcDYCaLyeDySvXH1
+            lCZGlzaaO/7XZXWRzn2ufTT5ggQ5PtitL2D6+ZISEmJSkjKk7gOZKw==
+            -----END AGE ENCRYPTED FILE-----
+          recipient: age1hd55swd904gh5pa625saj39c9m8w395xyy6ncc55cs2scydat4nq7kdvyv
+    lastmodified: "2026-10-15T08:16:29Z"
+    mac: ENC[AES256_GCM,data:y0i7REM1JTHm9uZxMHHFMBCGL++KhceteRKzQXPk+r4BoG93EIRbpR5YL+saASFn1mvPrEImPyKPintJVyuqD9VU0KloT3K0gSLin7kjlznb9Sm/lacAyB6VrjNap/PzYIIR6TMoNyFSrfx+nD1/kZza1wsxSX8MM6Ggwedg8Ks=,iv:Yx3jjiKEUD57ZJHv2dauK9zp770fG8GDnPCfPt2ng4U=,tag:uC253iDA3gOnZwV36Cf14A==,type:str]
+    unencrypted_suffix: _unencrypted
+    version: 3.13.3
+`
+
+// sopsTestJSON is a JSON file encrypted by sops
+const sopsTestJSON = `
+{
+	"token": "ENC[AES256_GCM,data:V5cuEXAeK9NAZRo=,iv:5Jcckjd8tkfr9ouAjGv5XoLH+R9hO4QJ5CUXezGJmAY=,tag:+YURC82UaF8R5zRegslyMA==,type:str]",
+	"threads": "ENC[AES256_GCM,data:wg==,iv:BgSqUJ6kAEhNPxoOq+YsDULGh4SXEdVrbCTS0dHUj5w=,tag:FPejfmRQDG6hb4o+09hghw==,type:int]",
+	"sops": {
+		"age": [
+			{
+				"enc": "-----BEGIN AGE ENCRYPTED FILE-----\nYWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBDWExFMjRoRTBHUGc0Q0VK\nbktmbmd6OFVsM1U2bGpIbzN0bTlGZWpIQjFFClFoVlgwUk5vMnEwcTh2a2I4Yzh0\nb25tK2xFMW0wMXJoUU5mY2JubmxFQ1EKLS0tIDRXOWRyY3dwckN5UFMrbmwzbVFj\nZTMyeXhpSzZRcmtISFA0enlDQmxkM3MKZOguqndwUU84FQ3aOt0oQF8hIX9FI8fJ\nVaDbO/1hlqKOs0MrjicdlMcNIZZiM7DiQekTxaw7bxI36YkmmT89Sw==\n-----END AGE ENCRYPTED FILE-----\n",
+				"recipient": "age1hd55swd904gh5pa625saj39c9m8w395xyy6ncc55cs2scydat4nq7kdvyv"
+			}
+		],
+		"lastmodified": "2026-10-15T08:16:29Z",
+		"mac": "ENC[AES256_GCM,data:j7smAZpVtW4ZpCg6T8IxDmVPtk4vgfUbFMlHu4LZL1Z9Vme3pZkrQolQ1ovYXby5adiP7bi32nwZNyAalEl3wKh9BXJHTDKAoTASEcnK8h5Ut9PUhZp2wm40Au/kkZ7T7YpY6HIYS4iVsuzpZy9vtPG6VRfsp5yvlEXv0wobASE=,iv:xeSSwksx64NPEd98nqZKWjFF/WkeLrOVQH29h9wWcNw=,tag:FcGZ5ODZqCUYlFum8oAb9w==,type:str]",
+		"unencrypted_suffix": "_unencrypted",
+		"version": "3.13.3"
+	}
+}
+`
+
+// sopsTestRegexYAML only has the keys matching its encrypted_regex encrypted
+const sopsTestRegexYAML = `
+threads: 4
+token: ENC[AES256_GCM,data:grDzt21EDibBCdtL,iv:OAXHMhwfq0M4IdLAm/sY0koa5d83uYynbqH1vDvl6n8=,tag:1SlSHxrGWp0A80uASJGovw==,type:str]
+sops:
+    age:
+        - enc: |
+            -----BEGIN AGE ENCRYPTED FILE-----
+            YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSByZ1p1QlhWWGpYV3psYjBP
+            bmNjb3BUQ3hYV2prVXlBRnhNVml3UlQ0eFRvCmlFUGJqVTRWR0pRd25pSTh3U0Yz
+            OUliZExYVEZtYklpK2gvSVVCMDdGYVUKLS0tIEt0RW05a29pUHJlN1ByY1NXVmJy
+            LzBGZ1NUemYzWFg0cEJ1OXJJMjM2UXMKA1zJ2wVNLCn0jSN9K/dv5i3eyXdMuKZa
+            8gwDQ+qR+JDBXxg3EqhbfzJ1I+qeJPjrVvF2aq2hBkuz7FCwrEZ1iw==
+            -----END AGE ENCRYPTED FILE-----
+          recipient: age1hd55swd904gh5pa625saj39c9m8w395xyy6ncc55cs2scydat4nq7kdvyv
+    encrypted_regex: ^token$
+    lastmodified: "2026-10-15T08:16:50Z"
+    mac: ENC[AES256_GCM,data:rAHilhVHV8ViC0EF3eLvGbbID/PHBbzJZ+b5F2+snpDtrJ3/iY8MQhwzWWbCZg0o5UYxCu5mXQqo9RttDavnhDk1+Gn3ivYbRgcQOBfmvjnUmRP0ceYqX3ScYswBB9MHrfak5KBUZVTnj3j/14you0B6sEOm1ZOuh+EpluU+0vM=,iv:N8hHLECoKfY3Hey3pKsX+ixmmHQrrUBqmF7mf14/9LQ=,tag:EH/s0qHvNAtkE6QqT7hk8g==,type:str]
+    version: 3.13.3
+`
+
+func TestSopsConfigFile(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var threads, port, retries int
+	var rate float64
+	var verbose bool
+	var token string
+	var targets StringSlice
+	flagSet.IntVar(&threads, "threads", 1, "Threads")
+	flagSet.IntVar(&port, "port_unencrypted", 80, "Port")
+	flagSet.FloatVar(&rate, "rate", 1, "Rate")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flagSet.StringVar(&token, "token", "", "API token")
+	flagSet.StringSliceVar(&targets, "targets", nil, "Targets")
+	scan := flagSet.NewCommand("scan", "Scan the targets")
+	scan.IntVar(&retries, "retries", 1, "Retries")
+
+	configFile := filepath.Join(tempDir, "config.yaml")
+	require.Nil(t, ioutil.WriteFile(configFile, []byte(sopsTestYAML), os.ModePerm), "could not write config")
+	err = flagSet.MergeConfigFile(configFile)
+	require.NotNil(t, err, "could decrypt sops file without key")
+
+	flagSet.SetConfigKey(sopsTestKey)
+	flagSet.SetConfigSearchPaths(configFile)
+	err = flagSet.ParseArgs([]string{"scan"})
+	require.Nil(t, err, "could not merge sops config file")
+	require.Equal(t, 10, threads)
+	require.Equal(t, 8080, port)
+	require.Equal(t, 1.5, rate)
+	require.True(t, verbose)
+	require.Equal(t, "secret token", token)
+	require.Equal(t, StringSlice{"a.com", "b.com"}, targets)
+	require.Equal(t, 3, retries)
+
+	jsonFile := filepath.Join(tempDir, "config.json")
+	require.Nil(t, ioutil.WriteFile(jsonFile, []byte(sopsTestJSON), os.ModePerm), "could not write config")
+	flagSet.Reset()
+	err = flagSet.MergeConfigFile(jsonFile)
+	require.Nil(t, err, "could not merge sops json config file")
+	require.Equal(t, 7, threads)
+	require.Equal(t, "json secret", token)
+
+	regexFile := filepath.Join(tempDir, "regex.yaml")
+	require.Nil(t, ioutil.WriteFile(regexFile, []byte(sopsTestRegexYAML), os.ModePerm), "could not write config")
+	flagSet.Reset()
+	err = flagSet.MergeConfigFile(regexFile)
+	require.Nil(t, err, "could not merge sops config file with encrypted regex")
+	require.Equal(t, 4, threads)
+	require.Equal(t, "regex secret", token)
+
+	tampered := strings.Replace(sopsTestYAML, "port_unencrypted: 8080", "port_unencrypted: 9090", 1)
+	require.Nil(t, ioutil.WriteFile(configFile, []byte(tampered), os.ModePerm), "could not write config")
+	err = flagSet.MergeConfigFile(configFile)
+	require.EqualError(t, err, "could not decrypt sops config file "+configFile+": mac mismatch, the file was modified without sops")
+}
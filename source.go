@@ -0,0 +1,57 @@
+package goflags
+
+import (
+	"net/url"
+	"path"
+
+	"github.com/pkg/errors"
+)
+
+// ConfigSource fetches the content of a config file stored at a location
+// with a URL scheme, like s3://bucket/tool/config.yaml.
+type ConfigSource func(location *url.URL) ([]byte, error)
+
+// configSources holds the config sources by URL scheme
+var configSources = make(map[string]ConfigSource)
+
+// RegisterConfigSource makes MergeConfigFile and the -config flag read config
+// files with the given URL scheme from the source, resolving credentials the
+// way the source sees fit. It is meant to be called from an init function,
+// for example to plug in object storage with the official cloud SDKs:
+//
+//	goflags.RegisterConfigSource("s3", func(location *url.URL) ([]byte, error) {
+//		output, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+//			Bucket: aws.String(location.Host),
+//			Key:    aws.String(strings.TrimPrefix(location.Path, "/")),
+//		})
+//		if err != nil {
+//			return nil, err
+//		}
+//		defer output.Body.Close()
+//		return io.ReadAll(output.Body)
+//	})
+//
+// HTTP(S) URLs are always fetched with MergeConfigURL and its default options.
+func RegisterConfigSource(scheme string, source ConfigSource) {
+	configSources[scheme] = source
+}
+
+// configSourceLocation returns the location of the config file
+// if it is stored in a registered config source.
+func configSourceLocation(file string) (*url.URL, ConfigSource, bool) {
+	location, err := url.Parse(file)
+	if err != nil || location.Scheme == "" {
+		return nil, nil, false
+	}
+	source, ok := configSources[location.Scheme]
+	return location, source, ok
+}
+
+// mergeConfigSource fetches a config file from its config source and merges it into the flags
+func (flagSet *FlagSet) mergeConfigSource(file string, location *url.URL, source ConfigSource) error {
+	content, err := source(location)
+	if err != nil {
+		return errors.Wrapf(err, "could not fetch config file from %s", location.Scheme)
+	}
+	return flagSet.mergeConfigContent(content, file, path.Ext(location.Path), false)
+}
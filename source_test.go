@@ -0,0 +1,33 @@
+package goflags
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterConfigSource(t *testing.T) {
+	RegisterConfigSource("memory", func(location *url.URL) ([]byte, error) {
+		if location.Host != "bucket" || location.Path != "/tool/config.ini" {
+			return nil, errors.New("object not found")
+		}
+		return []byte("threads = 10"), nil
+	})
+	defer delete(configSources, "memory")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	var threads int
+	flagSet.IntVar(&threads, "threads", 1, "Threads")
+
+	err := flagSet.MergeConfigFile("memory://bucket/tool/config.ini")
+	require.Nil(t, err, "could not merge config from source")
+	require.Equal(t, 10, threads)
+
+	err = flagSet.MergeConfigFile("memory://bucket/missing.yaml")
+	require.NotNil(t, err, "could merge missing config from source")
+
+	tearDown(t.Name())
+}
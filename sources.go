@@ -0,0 +1,103 @@
+package goflags
+
+import "flag"
+
+// SourceKind is the kind of source the value of a flag comes from
+type SourceKind int
+
+const (
+	// SourceDefault is the default value of the flag
+	SourceDefault SourceKind = iota
+	// SourceCLI is a value provided on the command line
+	SourceCLI
+	// SourceEnv is a value read from an environment variable or a .env file
+	SourceEnv
+	// SourceConfigFile is a value read from a config file or remote config source
+	SourceConfigFile
+	// SourceStdin is a value read from the standard input
+	SourceStdin
+	// SourcePrompt is a value typed at a password prompt
+	SourcePrompt
+	// SourceKeyring is a value read from the OS keyring
+	SourceKeyring
+	// SourceCustom is a value read from a value source added with AddSource
+	SourceCustom
+)
+
+// Source is where the value of a flag comes from
+type Source struct {
+	Kind SourceKind
+	// Name is the environment variable, config file or value source supplying the value
+	Name string
+	// File is the .env file defining the environment variable, if any
+	File string
+}
+
+func (source Source) String() string {
+	switch source.Kind {
+	case SourceCLI:
+		return "CLI"
+	case SourceEnv:
+		if source.File != "" {
+			return "ENV(" + source.Name + " from " + source.File + ")"
+		}
+		return "ENV(" + source.Name + ")"
+	case SourceConfigFile:
+		return "CONFIG_FILE(" + source.Name + ")"
+	case SourceStdin:
+		return "STDIN"
+	case SourcePrompt:
+		return "PROMPT"
+	case SourceKeyring:
+		return "KEYRING"
+	case SourceCustom:
+		return "SOURCE(" + source.Name + ")"
+	}
+	return "DEFAULT"
+}
+
+// Source returns where the value of a flag comes from after Parse
+func (flagSet *FlagSet) Source(name string) Source {
+	flagSet.valuesMutex.RLock()
+	source, ok := flagSet.flagSources[flagSet.canonicalName(name)]
+	flagSet.valuesMutex.RUnlock()
+	if owner, inherited := flagSet.inherited[name]; !ok && inherited {
+		return owner.Source(name)
+	}
+	return source
+}
+
+// Changed reports whether the value of a flag was explicitly provided by any
+// source, the source being returned by Source.
+func (flagSet *FlagSet) Changed(name string) bool {
+	return flagSet.Source(name).Kind != SourceDefault || flagSet.setOnCommandLine(name)
+}
+
+// setOnCommandLine reports whether a flag was set on its flag.FlagSet
+// under any of its names, even if it was not parsed by Parse.
+func (flagSet *FlagSet) setOnCommandLine(name string) bool {
+	names := []string{name}
+	if data, ok := flagSet.flagKeys.values[name]; ok {
+		names = []string{data.short, data.long}
+	}
+	var set bool
+	flagSet.CommandLine().Visit(func(fl *flag.Flag) {
+		set = set || sliceContains(names, fl.Name)
+	})
+	return set
+}
+
+// setSource records the source of the value of a flag
+func (flagSet *FlagSet) setSource(name string, source Source) {
+	if flagSet.flagSources == nil {
+		flagSet.flagSources = make(map[string]Source)
+	}
+	flagSet.flagSources[flagSet.canonicalName(name)] = source
+}
+
+// recordCLISources records the flags provided on the command line
+func (flagSet *FlagSet) recordCLISources() {
+	flagSet.CommandLine().Visit(func(fl *flag.Flag) {
+		flagSet.setSource(fl.Name, Source{Kind: SourceCLI})
+	})
+}
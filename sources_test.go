@@ -0,0 +1,71 @@
+package goflags
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSource(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("rate-limit: 10"), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	os.Setenv("GOFLAGS_TEST_PROXY", "http://proxy:8080")
+	defer os.Unsetenv("GOFLAGS_TEST_PROXY")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.SetConfigFilePath(configFile)
+	var threads, rateLimit int
+	var proxy, output string
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads")
+	flagSet.IntVar(&rateLimit, "rate-limit", 1, "Rate limit")
+	flagSet.StringVar(&proxy, "proxy", "", "Proxy").Env("GOFLAGS_TEST_PROXY")
+	flagSet.StringVar(&output, "output", "", "Output file")
+
+	os.Args = []string{os.Args[0], "-t", "5"}
+	err = flagSet.Parse()
+	require.Nil(t, err, "could not parse flags")
+
+	require.Equal(t, Source{Kind: SourceCLI}, flagSet.Source("threads"))
+	require.Equal(t, Source{Kind: SourceConfigFile, Name: configFile}, flagSet.Source("rate-limit"))
+	require.Equal(t, "ENV(GOFLAGS_TEST_PROXY)", flagSet.Source("proxy").String())
+	require.Equal(t, Source{Kind: SourceDefault}, flagSet.Source("output"))
+
+	tearDown(t.Name())
+}
+
+func TestChanged(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("threads: 50\nretries: 5"), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath(configFile)
+	var threads, retries, rate int
+	flagSet.IntVarP(&threads, "threads", "t", 10, "Threads")
+	flagSet.IntVar(&retries, "retries", 1, "Retries")
+	flagSet.IntVar(&rate, "rate", 100, "Rate")
+
+	require.Nil(t, flagSet.ParseArgs([]string{"-t", "10"}), "could not parse flags")
+	require.Equal(t, 10, threads, "could override cli value equal to the default")
+	require.Equal(t, 5, retries)
+	require.True(t, flagSet.Changed("t"))
+	require.Equal(t, SourceCLI, flagSet.Source("threads").Kind)
+	require.True(t, flagSet.Changed("retries"))
+	require.Equal(t, SourceConfigFile, flagSet.Source("retries").Kind)
+	require.False(t, flagSet.Changed("rate"))
+}
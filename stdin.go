@@ -0,0 +1,95 @@
+package goflags
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// stdinValue is the value used to request reading a flag value from stdin
+const stdinValue = "-"
+
+// AllowStdin makes the flag read its value from stdin when "-" is passed as value.
+//
+// String flags receive the whole input with the trailing newline removed,
+// while slice flags receive every non-empty line of the input as an item.
+func (flagData *FlagData) AllowStdin() *FlagData {
+	flagData.stdin = true
+	return flagData
+}
+
+// readStdinValues replaces the "-" values of the flags allowing it with
+// the data read from stdin. Stdin can only be consumed by a single flag.
+func (flagSet *FlagSet) readStdinValues() error {
+	var consumedBy string
+	var err error
+
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if err != nil || !data.stdin || key != data.name() {
+			return
+		}
+		currentFlag := flagSet.CommandLine().Lookup(key)
+		if currentFlag == nil || !hasStdinValue(unwrapValue(currentFlag.Value)) {
+			return
+		}
+		if consumedBy != "" {
+			err = errors.Errorf("could not read -%s from stdin: already consumed by -%s", key, consumedBy)
+			return
+		}
+		consumedBy = key
+
+		input, readErr := ioutil.ReadAll(flagSet.stdin)
+		if readErr != nil {
+			err = errors.Wrapf(readErr, "could not read -%s from stdin", key)
+			return
+		}
+		if setErr := setStdinValue(unwrapValue(currentFlag.Value), input); setErr != nil {
+			err = errors.Wrapf(setErr, "could not set -%s from stdin", key)
+			return
+		}
+		flagSet.setSource(key, Source{Kind: SourceStdin})
+	})
+	return err
+}
+
+func hasStdinValue(value flag.Value) bool {
+	if stringSlice, ok := value.(*StringSlice); ok {
+		for _, item := range *stringSlice {
+			if item == stdinValue {
+				return true
+			}
+		}
+		return false
+	}
+	return value.String() == stdinValue
+}
+
+func setStdinValue(value flag.Value, input []byte) error {
+	stringSlice, ok := value.(*StringSlice)
+	if !ok {
+		return value.Set(string(bytes.TrimRight(input, "\r\n")))
+	}
+
+	items := make(StringSlice, 0, len(*stringSlice))
+	for _, item := range *stringSlice {
+		if item != stdinValue {
+			items = append(items, item)
+		}
+	}
+	*stringSlice = items
+
+	scanner := bufio.NewScanner(bytes.NewReader(input))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := stringSlice.Set(string(line)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
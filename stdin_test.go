@@ -0,0 +1,75 @@
+package goflags
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadStdinValues(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		flagSet := NewFlagSet()
+		flagSet.stdin = strings.NewReader("payload\n")
+
+		var data string
+		flagSet.StringVarP(&data, "input", "i", "", "Input value").AllowStdin()
+
+		err := flag.CommandLine.Parse([]string{"-i", "-"})
+		require.Nil(t, err, "could not parse flags")
+		err = flagSet.readStdinValues()
+		require.Nil(t, err, "could not read stdin values")
+		require.Equal(t, "payload", data, "could not get correct string")
+
+		tearDown(t.Name())
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		flagSet := NewFlagSet()
+		flagSet.stdin = strings.NewReader("a.com\n\nb.com\n")
+
+		var data StringSlice
+		flagSet.StringSliceVar(&data, "targets", nil, "Targets to scan").AllowStdin()
+
+		err := flag.CommandLine.Parse([]string{"-targets", "c.com", "-targets", "-"})
+		require.Nil(t, err, "could not parse flags")
+		err = flagSet.readStdinValues()
+		require.Nil(t, err, "could not read stdin values")
+		require.Equal(t, StringSlice{"c.com", "a.com", "b.com"}, data, "could not get correct string slice")
+
+		tearDown(t.Name())
+	})
+
+	t.Run("not-allowed", func(t *testing.T) {
+		flagSet := NewFlagSet()
+		flagSet.stdin = strings.NewReader("payload")
+
+		var data string
+		flagSet.StringVar(&data, "input", "", "Input value")
+
+		err := flag.CommandLine.Parse([]string{"-input", "-"})
+		require.Nil(t, err, "could not parse flags")
+		err = flagSet.readStdinValues()
+		require.Nil(t, err, "could not read stdin values")
+		require.Equal(t, "-", data, "could not get correct string")
+
+		tearDown(t.Name())
+	})
+
+	t.Run("consumed-twice", func(t *testing.T) {
+		flagSet := NewFlagSet()
+		flagSet.stdin = strings.NewReader("payload")
+
+		var data, data2 string
+		flagSet.StringVar(&data, "input", "", "Input value").AllowStdin()
+		flagSet.StringVar(&data2, "input2", "", "Input value #2").AllowStdin()
+
+		err := flag.CommandLine.Parse([]string{"-input", "-", "-input2", "-"})
+		require.Nil(t, err, "could not parse flags")
+		err = flagSet.readStdinValues()
+		require.NotNil(t, err, "could read stdin twice")
+
+		tearDown(t.Name())
+	})
+}
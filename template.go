@@ -0,0 +1,123 @@
+package goflags
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// UsageData is the content of the usage passed to the usage template,
+// and written as JSON when the usage is requested with -help=json.
+type UsageData struct {
+	Header      string           `json:"header,omitempty"` // text set with SetHelpHeader
+	Description string           `json:"description,omitempty"`
+	Version     string           `json:"version,omitempty"` // version set with SetVersion, with its commit and date
+	Usage       string           `json:"usage"`             // usage line, the command path followed by the arguments
+	Commands    []UsageCommand   `json:"commands,omitempty"`
+	Arguments   []UsageArgument  `json:"arguments,omitempty"`
+	Groups      []UsageGroup     `json:"groups"` // flags without a group first, followed by the groups with flags
+	Constraints UsageConstraints `json:"constraints"`
+	Examples    []UsageExample   `json:"examples,omitempty"`
+	Footer      string           `json:"footer,omitempty"` // text set with SetHelpFooter
+}
+
+// UsageCommand is a command of the FlagSet
+type UsageCommand struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Default     bool     `json:"default,omitempty"`
+}
+
+// UsageArgument is a positional argument of the FlagSet
+type UsageArgument struct {
+	Name     string `json:"name"`
+	Usage    string `json:"usage,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	Variadic bool   `json:"variadic,omitempty"`
+}
+
+// UsageGroup is a group of flags, with an empty name for the flags without a group
+type UsageGroup struct {
+	Name  string      `json:"name,omitempty"`
+	Title string      `json:"title,omitempty"`
+	Flags []*FlagInfo `json:"flags"`
+}
+
+// UsageConstraints are the constraints between the flags of the FlagSet
+type UsageConstraints struct {
+	RequiredTogether [][]string         `json:"requiredTogether,omitempty"`
+	OneRequired      [][]string         `json:"oneRequired,omitempty"`
+	Conditional      []UsageRequirement `json:"conditional,omitempty"`
+}
+
+// UsageRequirement is a flag required under a condition, described by the reason if it is known
+type UsageRequirement struct {
+	Flag   string `json:"flag"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// UsageExample is an example invocation of the program
+type UsageExample struct {
+	Description string `json:"description"`
+	Command     string `json:"command"`
+}
+
+// SetUsageTemplate sets a text/template rendering the usage instead of the
+// default layout, executed with the UsageData of the FlagSet.
+func (flagSet *FlagSet) SetUsageTemplate(text string) error {
+	usageTemplate, err := template.New("usage").Parse(text)
+	if err != nil {
+		return err
+	}
+	flagSet.usageTemplate = usageTemplate
+	return nil
+}
+
+// UsageData returns the content of the usage of the FlagSet
+func (flagSet *FlagSet) UsageData() *UsageData {
+	data := &UsageData{Header: flagSet.helpHeader, Description: flagSet.description, Footer: flagSet.helpFooter}
+	if flagSet.version.version != "" {
+		data.Version = flagSet.version.String()
+	}
+	data.Usage = flagSet.usageLine()
+	for _, command := range flagSet.commands {
+		data.Commands = append(data.Commands, UsageCommand{
+			Name:        command.commandName,
+			Aliases:     command.aliases,
+			Description: command.description,
+			Default:     command.commandName == flagSet.defaultCommand,
+		})
+	}
+	for _, arg := range flagSet.positionals {
+		data.Arguments = append(data.Arguments, UsageArgument{Name: arg.name, Usage: arg.usage, Required: arg.required, Variadic: arg.variadic})
+	}
+
+	for _, section := range flagSet.usageSections(func(data *FlagData) bool { return !data.configOnly && !data.hidden }, false) {
+		usageGroup := UsageGroup{Name: section.group.name}
+		if section.group.name != "" {
+			usageGroup.Title = section.group.title()
+		}
+		for _, flagData := range section.flags {
+			usageGroup.Flags = append(usageGroup.Flags, flagSet.flagInfo(flagData))
+		}
+		data.Groups = append(data.Groups, usageGroup)
+	}
+
+	data.Constraints.RequiredTogether = flagSet.requiredTogether
+	data.Constraints.OneRequired = flagSet.oneRequired
+	for _, requirement := range flagSet.requirements {
+		data.Constraints.Conditional = append(data.Constraints.Conditional, UsageRequirement{Flag: requirement.name, Reason: requirement.reason})
+	}
+	for _, example := range flagSet.examples {
+		data.Examples = append(data.Examples, UsageExample{Description: example.description, Command: example.command})
+	}
+	return data
+}
+
+// executeUsageTemplate writes the usage rendered with the usage template
+func (flagSet *FlagSet) executeUsageTemplate() {
+	output := flagSet.CommandLine().Output()
+	if err := flagSet.usageTemplate.Execute(output, flagSet.UsageData()); err != nil {
+		fmt.Fprintf(output, "could not render usage: %s\n", err)
+	}
+}
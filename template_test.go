@@ -0,0 +1,43 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageTemplate(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.SetDescription("Scanner")
+	var target, output string
+	var threads int
+	flagSet.AddPositional(&target, "target", "Target to scan", true)
+	flagSet.IntVarP(&threads, "threads", "t", 10, "Threads")
+	flagSet.CreateGroup("output", "Output", flagSet.StringVarP(&output, "output", "o", "", "Output file"))
+	flagSet.AddExample("scan a host", "tool example.com")
+
+	err := flagSet.SetUsageTemplate(`{{.Description}}
+{{range .Arguments}}<{{.Name}}> {{.Usage}}
+{{end}}{{range .Groups}}[{{.Title}}]
+{{range .Flags}}-{{.Name}} {{.Type}} {{.DefaultValue}}
+{{end}}{{end}}{{range .Examples}}$ {{.Command}}
+{{end}}`)
+	require.Nil(t, err, "could not parse usage template")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Equal(t, "Scanner\n"+
+		"<target> Target to scan\n"+
+		"[]\n"+
+		"-threads int 10\n"+
+		"[OUTPUT]\n"+
+		"-output string \n"+
+		"$ tool example.com\n", usage.String())
+
+	err = flagSet.SetUsageTemplate("{{.Missing")
+	require.NotNil(t, err, "invalid template was accepted")
+}
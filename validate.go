@@ -0,0 +1,172 @@
+package goflags
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Validate adds a validator run once all sources are merged, which receives the
+// typed value of the flag: a string, bool, int or time.Duration for the flags of
+// these types, a []string for slice flags, a []byte for hex flags, and the
+// flag.Value itself for custom values. Errors of all flags are reported together.
+func (flagData *FlagData) Validate(validator func(value interface{}) error) *FlagData {
+	flagData.validators = append(flagData.validators, validator)
+	return flagData
+}
+
+// WithMin makes Parse reject values of an int, float or duration flag lower than min,
+// which is an int, float64 or time.Duration.
+func (flagData *FlagData) WithMin(min interface{}) *FlagData {
+	flagData.min = min
+	return flagData
+}
+
+// WithMax makes Parse reject values of an int, float or duration flag greater than max,
+// which is an int, float64 or time.Duration.
+func (flagData *FlagData) WithMax(max interface{}) *FlagData {
+	flagData.max = max
+	return flagData
+}
+
+// Pattern makes Parse reject values of a string or string slice flag not matching
+// the regular expression, whatever their source. Empty values are not checked.
+//
+// NOTE: Pattern panics if the regular expression is invalid, unless the FlagSet is in library mode.
+func (flagData *FlagData) Pattern(pattern string) *FlagData {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		flagData.fail(errors.Wrapf(err, "invalid pattern for -%s", flagData.name()))
+		return flagData
+	}
+	return flagData.Validate(func(value interface{}) error {
+		var items []string
+		switch value := value.(type) {
+		case string:
+			items = []string{value}
+		case []string:
+			items = value
+		default:
+			return errors.Errorf("patterns are not supported for values of type %T", value)
+		}
+		for _, item := range items {
+			if item != "" && !re.MatchString(item) {
+				return errors.Errorf("%q does not match pattern %s", item, pattern)
+			}
+		}
+		return nil
+	})
+}
+
+// SetValidationFunc sets a function called at the end of Parse, once all the flags
+// are resolved and validated, to check invariants spanning several flags.
+// Its error is returned by Parse.
+func (flagSet *FlagSet) SetValidationFunc(validationFunc func(flagSet *FlagSet) error) {
+	flagSet.validationFunc = validationFunc
+}
+
+// typedValue returns the value of a flag as passed to its validators
+func typedValue(value flag.Value) interface{} {
+	switch value := value.(type) {
+	case *StringSlice:
+		return []string(*value)
+	case *enumValue:
+		return value.String()
+	case *enumSliceValue:
+		return *value.field
+	case *multiChoiceValue:
+		return *value.field
+	case *hexBytesValue:
+		return *value.field
+	case flag.Getter:
+		return value.Get()
+	}
+	return value
+}
+
+// runValidators runs the validators of the flags, returning their errors together
+func (flagSet *FlagSet) runValidators() error {
+	var errs Errors
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		currentFlag := flagSet.CommandLine().Lookup(key)
+		if (len(data.validators) == 0 && data.min == nil && data.max == nil) || key != data.name() || currentFlag == nil {
+			return
+		}
+		value := typedValue(unwrapValue(currentFlag.Value))
+		if err := checkBounds(data, value); err != nil {
+			errs.add(&FlagValueError{Flag: key, Err: err, SeeAlso: data.related})
+			return
+		}
+		for _, validator := range data.validators {
+			if err := validator(value); err != nil {
+				errs.add(&FlagValueError{Flag: key, Err: err, SeeAlso: data.related})
+				return
+			}
+		}
+	})
+	return errs.err()
+}
+
+// FlagValueError is a flag value rejected by a validator
+type FlagValueError struct {
+	Flag    string
+	Err     error
+	SeeAlso []string // flags related to the flag, set with SeeAlso
+}
+
+func (err *FlagValueError) Error() string {
+	return "invalid value for -" + err.Flag + ": " + err.Err.Error() + seeAlsoHint(err.SeeAlso)
+}
+
+// checkBounds checks a numeric value against the bounds of its flag
+func checkBounds(data *FlagData, value interface{}) error {
+	if data.min == nil && data.max == nil {
+		return nil
+	}
+	number, ok := numericValue(value)
+	if !ok {
+		return errors.Errorf("bounds are not supported for values of type %T", value)
+	}
+	if min, ok := numericValue(data.min); ok && number < min {
+		return errors.Errorf("must be at least %v", data.min)
+	}
+	if max, ok := numericValue(data.max); ok && number > max {
+		return errors.Errorf("must be at most %v", data.max)
+	}
+	return nil
+}
+
+// numericValue returns a number or duration as a float64
+func numericValue(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case uint:
+		return float64(value), true
+	case uint64:
+		return float64(value), true
+	case float64:
+		return value, true
+	case time.Duration:
+		return float64(value), true
+	}
+	return 0, false
+}
+
+// createUsageBounds returns the bounds of a numeric flag as shown in the usage
+func createUsageBounds(data *FlagData) string {
+	switch {
+	case data.min != nil && data.max != nil:
+		return fmt.Sprintf(" (%v-%v)", data.min, data.max)
+	case data.min != nil:
+		return fmt.Sprintf(" (>=%v)", data.min)
+	case data.max != nil:
+		return fmt.Sprintf(" (<=%v)", data.max)
+	}
+	return ""
+}
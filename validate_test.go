@@ -0,0 +1,177 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	flagSet := newConstraintsFlagSet(t.Name())
+	var threads int
+	var timeout time.Duration
+	var targets StringSlice
+	var output string
+	flagSet.IntVarP(&threads, "threads", "t", 10, "Threads").Validate(func(value interface{}) error {
+		if value.(int) <= 0 {
+			return errors.New("must be positive")
+		}
+		return nil
+	})
+	flagSet.DurationVar(&timeout, "timeout", time.Second, "Timeout").Validate(func(value interface{}) error {
+		if value.(time.Duration) > time.Minute {
+			return errors.New("must be at most 1m")
+		}
+		return nil
+	})
+	flagSet.StringSliceVar(&targets, "targets", nil, "Targets").Validate(func(value interface{}) error {
+		for _, target := range value.([]string) {
+			if strings.Contains(target, " ") {
+				return errors.Errorf("invalid target %q", target)
+			}
+		}
+		return nil
+	})
+	flagSet.StringVar(&output, "output", "", "Output file")
+
+	usage := &bytes.Buffer{}
+	flag.CommandLine.SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "-threads int", "could not show flag with validator")
+	require.Contains(t, usage.String(), "-timeout duration", "could not show flag with validator")
+
+	os.Args = []string{os.Args[0], "-t", "0", "-timeout", "2m", "-targets", "a.com"}
+	err := flagSet.Parse()
+	require.NotNil(t, err, "could parse invalid values")
+	require.Equal(t, "invalid value for -threads: must be positive\ninvalid value for -timeout: must be at most 1m", err.Error())
+
+	errs, ok := err.(Errors)
+	require.True(t, ok, "could not get aggregated errors")
+	require.Equal(t, "threads", errs[0].(*FlagValueError).Flag)
+
+	flagSet = newConstraintsFlagSet(t.Name())
+	flagSet.IntVar(&threads, "threads", 10, "Threads").Validate(func(value interface{}) error {
+		return errors.New("always invalid")
+	})
+	os.Args = []string{os.Args[0]}
+	require.NotNil(t, flagSet.Parse(), "could skip validation of default value")
+
+	tearDown(t.Name())
+}
+
+func TestBounds(t *testing.T) {
+	flagSet := newConstraintsFlagSet(t.Name())
+	var threads int
+	var rate float64
+	var timeout time.Duration
+	flagSet.IntVarP(&threads, "threads", "t", 10, "Threads").WithMin(1).WithMax(500)
+	flagSet.FloatVar(&rate, "rate", 1, "Rate").WithMin(0.5)
+	flagSet.DurationVar(&timeout, "timeout", time.Second, "Timeout").WithMax(time.Minute)
+
+	usage := &bytes.Buffer{}
+	flag.CommandLine.SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "-threads int (1-500)")
+	require.Contains(t, usage.String(), "-rate float (>=0.5)")
+	require.Contains(t, usage.String(), "-timeout duration (<=1m0s)")
+
+	os.Args = []string{os.Args[0], "-t", "1000", "-rate", "0.1", "-timeout", "30s"}
+	err := flagSet.Parse()
+	require.NotNil(t, err, "could parse out of bounds values")
+	require.Equal(t, "invalid value for -threads: must be at most 500\ninvalid value for -rate: must be at least 0.5", err.Error())
+
+	flagSet = newConstraintsFlagSet(t.Name())
+	flagSet.IntVarP(&threads, "threads", "t", 10, "Threads").WithMin(1).WithMax(500)
+	os.Args = []string{os.Args[0], "-t", "500"}
+	require.Nil(t, flagSet.Parse(), "could not parse value within bounds")
+	require.Equal(t, 500, threads)
+
+	tearDown(t.Name())
+}
+
+func TestPattern(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("domains:\n  - example.com\n  - bad domain"), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	os.Setenv("GOFLAGS_TEST_PROJECT", "my project")
+	defer os.Unsetenv("GOFLAGS_TEST_PROJECT")
+
+	flagSet := newConstraintsFlagSet(t.Name())
+	flagSet.SetConfigFilePath(configFile)
+	var id, project string
+	var domains StringSlice
+	flagSet.StringVar(&id, "id", "", "Identifier").Pattern(`^[a-z][a-z0-9-]*$`)
+	flagSet.StringVar(&project, "project", "", "Project").Env("GOFLAGS_TEST_PROJECT").Pattern(`^\S+$`)
+	flagSet.StringSliceVar(&domains, "domains", nil, "Domains").Pattern(`^[a-z0-9.-]+$`)
+
+	os.Args = []string{os.Args[0], "-id", "1abc"}
+	err = flagSet.Parse()
+	require.NotNil(t, err, "could parse values not matching pattern")
+	require.Equal(t, `invalid value for -id: "1abc" does not match pattern ^[a-z][a-z0-9-]*$
+invalid value for -project: "my project" does not match pattern ^\S+$
+invalid value for -domains: "bad domain" does not match pattern ^[a-z0-9.-]+$`, err.Error())
+
+	flagSet = newConstraintsFlagSet(t.Name())
+	flagSet.StringVar(&id, "id", "", "Identifier").Pattern(`^[a-z][a-z0-9-]*$`)
+	os.Args = []string{os.Args[0], "-id", "abc-1"}
+	require.Nil(t, flagSet.Parse(), "could not parse value matching pattern")
+	require.Panics(t, func() { flagSet.StringVar(&project, "project", "", "Project").Pattern("[") })
+
+	tearDown(t.Name())
+}
+
+func TestEnumValidators(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var format string
+	var validated interface{}
+	flagSet.EnumVar(&format, "format", "json", []string{"json", "jsonl", "yaml"}, "Output format").
+		Pattern(`^json`).
+		Validate(func(value interface{}) error {
+			validated = value
+			return nil
+		})
+
+	require.Nil(t, flagSet.ParseArgs([]string{"-format", "jsonl"}), "could not validate enum value")
+	require.Equal(t, "jsonl", validated, "validator did not receive the enum string")
+
+	err := flagSet.ParseArgs([]string{"-format", "yaml"})
+	require.NotNil(t, err, "could parse enum value not matching pattern")
+	require.Equal(t, `invalid value for -format: "yaml" does not match pattern ^json`, err.Error())
+}
+
+func TestValidationFunc(t *testing.T) {
+	flagSet := newConstraintsFlagSet(t.Name())
+	var start, end time.Duration
+	flagSet.DurationVar(&start, "start", 0, "Start time")
+	flagSet.DurationVar(&end, "end", time.Hour, "End time")
+	flagSet.SetValidationFunc(func(flagSet *FlagSet) error {
+		if end <= start {
+			return errors.New("end time must be after start time")
+		}
+		return nil
+	})
+
+	os.Args = []string{os.Args[0], "-start", "2h", "-end", "1h"}
+	err := flagSet.Parse()
+	require.NotNil(t, err, "could parse invalid flag combination")
+	require.Equal(t, "end time must be after start time", err.Error())
+
+	os.Args = []string{os.Args[0], "-start", "1h", "-end", "2h"}
+	require.Nil(t, flagSet.Parse(), "could not parse valid flag combination")
+
+	tearDown(t.Name())
+}
@@ -0,0 +1,148 @@
+package goflags
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// flagValue wraps the flag.Value of a registered flag so that
+// the values set through it can be processed by the FlagSet.
+type flagValue struct {
+	flag.Value
+	flagSet *FlagSet
+	data    *FlagData
+	inputs  []string
+}
+
+// Set processes the value before setting it on the wrapped flag.Value
+func (value *flagValue) Set(input string) error {
+	if value.flagSet.frozen {
+		return errors.Errorf("flag -%s can't be set after Parse", value.data.name())
+	}
+	if len(value.inputs) > 0 && (value.flagSet.SingleOccurrence || value.data.singleOccurrence) && !isSliceValue(value.Value) {
+		return errors.Errorf("flag can only be provided once, got %q and %q", value.inputs[0], input)
+	}
+	value.inputs = append(value.inputs, input)
+
+	if value.flagSet.ExpandEnv {
+		input = value.flagSet.expandEnv(input)
+	}
+	return value.Value.Set(input)
+}
+
+// SingleOccurrence makes providing the flag more than once a parse error,
+// unless the flag accepts multiple values.
+func (flagData *FlagData) SingleOccurrence() *FlagData {
+	flagData.singleOccurrence = true
+	return flagData
+}
+
+// IsBoolFlag reports whether the wrapped flag.Value can be set without a value
+func (value *flagValue) IsBoolFlag() bool {
+	boolFlag, ok := value.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// addFlag registers the flag.Value under the given names and stores its metadata
+func (flagSet *FlagSet) addFlag(value flag.Value, flagData *FlagData, names ...string) *FlagData {
+	flagData.flagSet = flagSet
+	if err := flagSet.checkFrozen(flagData.name()); err != nil {
+		flagSet.fail(err)
+		return flagData
+	}
+	flagData.registered = registeredValue{value: copyValue(value), text: value.String(), defaultValue: flagData.defaultValue}
+	if flagSet.LibraryMode {
+		if err := flagSet.checkFlagNames(flagData, names); err != nil {
+			flagSet.registrationErrs.add(err)
+			return flagData
+		}
+	}
+
+	wrapped := &flagValue{Value: value, flagSet: flagSet, data: flagData}
+	for _, name := range names {
+		flagSet.CommandLine().Var(wrapped, name, flagData.usage)
+		flagSet.flagKeys.Set(name, flagData)
+	}
+	return flagData
+}
+
+// checkFlagNames returns an error if the flag can't be registered under the given names
+func (flagSet *FlagSet) checkFlagNames(flagData *FlagData, names []string) error {
+	if !isNotBlank(flagData.short) && !isNotBlank(flagData.long) {
+		return errors.New("flag must have a name")
+	}
+	for _, name := range names {
+		switch {
+		case strings.HasPrefix(name, "-"):
+			return errors.Errorf("flag %q begins with -", name)
+		case strings.Contains(name, "="):
+			return errors.Errorf("flag %q contains =", name)
+		case flagSet.CommandLine().Lookup(name) != nil:
+			return errors.Errorf("flag redefined: %s", name)
+		}
+	}
+	return nil
+}
+
+// checkFrozen returns an error if the FlagSet is frozen by Parse
+func (flagSet *FlagSet) checkFrozen(name string) error {
+	if flagSet.frozen {
+		return errors.Errorf("flag %s registered after Parse", name)
+	}
+	return nil
+}
+
+// fail panics with the error of a registration option, unless the flag
+// belongs to a FlagSet in library mode which reports it from Parse.
+func (flagData *FlagData) fail(err error) {
+	if flagData.flagSet == nil {
+		panic(err.Error())
+	}
+	flagData.flagSet.fail(err)
+}
+
+// fail panics with a registration error, unless the FlagSet
+// is in library mode which reports it from Parse.
+func (flagSet *FlagSet) fail(err error) {
+	if !flagSet.LibraryMode {
+		panic(err.Error())
+	}
+	flagSet.registrationErrs.add(err)
+}
+
+// stdlibValue returns the flag.Value created by the standard
+// library for the flag defined by the define function.
+func stdlibValue(define func(set *flag.FlagSet)) flag.Value {
+	set := flag.NewFlagSet("", flag.ContinueOnError)
+	define(set)
+
+	var value flag.Value
+	set.VisitAll(func(fl *flag.Flag) {
+		value = fl.Value
+	})
+	return value
+}
+
+// unwrapValue returns the original flag.Value of a registered flag
+func unwrapValue(value flag.Value) flag.Value {
+	if wrapped, ok := value.(*flagValue); ok {
+		return wrapped.Value
+	}
+	return value
+}
+
+// isSliceValue returns true if the flag.Value accumulates multiple values
+func isSliceValue(value flag.Value) bool {
+	switch value.(type) {
+	case *enumSliceValue, *multiChoiceValue:
+		return true
+	}
+	valueType := reflect.TypeOf(value)
+	if valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	return valueType.Kind() == reflect.Slice || valueType.Kind() == reflect.Array
+}
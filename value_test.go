@@ -0,0 +1,85 @@
+package goflags
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSingleOccurrence(t *testing.T) {
+	parse := func(args ...string) error {
+		flag.CommandLine.Init(t.Name(), flag.ContinueOnError)
+		flag.CommandLine.SetOutput(ioutil.Discard)
+		return flag.CommandLine.Parse(args)
+	}
+
+	t.Run("flagset", func(t *testing.T) {
+		tearDown(t.Name())
+		flagSet := NewFlagSet()
+		flagSet.SingleOccurrence = true
+
+		var output string
+		var targets StringSlice
+		flagSet.StringVarP(&output, "output", "o", "", "Output file")
+		flagSet.StringSliceVar(&targets, "target", nil, "Targets")
+
+		err := parse("-target", "a", "-target", "b")
+		require.Nil(t, err, "could not parse repeated slice flag")
+		require.Equal(t, StringSlice{"a", "b"}, targets)
+
+		err = parse("-o", "a.txt", "-output", "b.txt")
+		require.NotNil(t, err, "could parse repeated flag")
+		require.Contains(t, err.Error(), `"a.txt" and "b.txt"`)
+
+		tearDown(t.Name())
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		tearDown(t.Name())
+		flagSet := NewFlagSet()
+
+		var output, other string
+		flagSet.StringVar(&output, "output", "", "Output file").SingleOccurrence()
+		flagSet.StringVar(&other, "other", "", "Other value")
+
+		err := parse("-other", "a", "-other", "b")
+		require.Nil(t, err, "could not parse repeated flag")
+		require.Equal(t, "b", other)
+
+		err = parse("-output", "a.txt", "-output", "b.txt")
+		require.NotNil(t, err, "could parse repeated flag")
+
+		tearDown(t.Name())
+	})
+}
+
+func TestFreezeAfterParse(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	flagSet.FreezeAfterParse = true
+	var output, target, late string
+	flagSet.StringVar(&output, "output", "", "Output file")
+
+	err := flagSet.ParseArgs([]string{"-output", "out.txt"})
+	require.Nil(t, err, "could not parse flags")
+
+	err = flagSet.CommandLine().Set("output", "other.txt")
+	require.EqualError(t, err, "flag -output can't be set after Parse")
+	require.Equal(t, "out.txt", output)
+	require.PanicsWithValue(t, "flag late registered after Parse", func() {
+		flagSet.StringVar(&late, "late", "", "Registered too late")
+	})
+	require.PanicsWithValue(t, "flag target registered after Parse", func() {
+		flagSet.AddPositional(&target, "target", "Target", false)
+	})
+
+	err = flagSet.ParseArgs([]string{"-output", "again.txt"})
+	require.Nil(t, err, "could not parse flags again")
+	require.Equal(t, "again.txt", output)
+
+	flagSet.Reset()
+	err = flagSet.CommandLine().Set("output", "reset.txt")
+	require.Nil(t, err, "could not set flag after reset")
+}
@@ -0,0 +1,62 @@
+package goflags
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ValueSource provides flag values from an external provider, like a database
+// or a feature flag service.
+type ValueSource interface {
+	// Name identifies the source in errors and in the Source of the flags it sets
+	Name() string
+	// Lookup returns the value of a flag by its long name, if the source has one
+	Lookup(flagName string) (string, bool)
+}
+
+// prioritizedSource is a value source with its priority
+type prioritizedSource struct {
+	source   ValueSource
+	priority int
+}
+
+// AddSource adds a value source used by Parse for the flags which were not set on the
+// command line, from the environment or config files. When several sources have a value
+// for a flag, the one with the highest priority wins, or the one added first on ties.
+func (flagSet *FlagSet) AddSource(source ValueSource, priority int) {
+	flagSet.valueSources = append(flagSet.valueSources, prioritizedSource{source: source, priority: priority})
+	sort.SliceStable(flagSet.valueSources, func(i, j int) bool {
+		return flagSet.valueSources[i].priority > flagSet.valueSources[j].priority
+	})
+}
+
+// applyValueSources sets the flags still having their default value from the value sources
+func (flagSet *FlagSet) applyValueSources() error {
+	if len(flagSet.valueSources) == 0 {
+		return nil
+	}
+	var err error
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if err != nil || key != data.name() || flagSet.Source(key).Kind != SourceDefault {
+			return
+		}
+		currentFlag := flagSet.CommandLine().Lookup(key)
+		if currentFlag == nil {
+			return
+		}
+		for _, item := range flagSet.valueSources {
+			value, ok := item.source.Lookup(key)
+			if !ok {
+				continue
+			}
+			if err = replaceValue(unwrapValue(currentFlag.Value), value); err != nil {
+				err = errors.Wrapf(err, "invalid value %q for -%s from %s", value, key, item.source.Name())
+				return
+			}
+			flagSet.setSource(key, Source{Kind: SourceCustom, Name: item.source.Name()})
+			return
+		}
+	})
+	return err
+}
@@ -0,0 +1,57 @@
+package goflags
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mapSource is a value source backed by a map
+type mapSource struct {
+	name   string
+	values map[string]string
+}
+
+func (source *mapSource) Name() string { return source.name }
+
+func (source *mapSource) Lookup(flagName string) (string, bool) {
+	value, ok := source.values[flagName]
+	return value, ok
+}
+
+func TestAddSource(t *testing.T) {
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.SetConfigFilePath("")
+	flagSet.AddSource(&mapSource{name: "low", values: map[string]string{"threads": "5", "output": "low.txt"}}, 1)
+	flagSet.AddSource(&mapSource{name: "high", values: map[string]string{"threads": "20", "targets": "a.com,b.com"}}, 10)
+	var threads int
+	var output, cli string
+	var targets StringSlice
+	flagSet.IntVarP(&threads, "threads", "t", 1, "Threads")
+	flagSet.StringVar(&output, "output", "", "Output file")
+	flagSet.StringVar(&cli, "cli", "", "Cli value")
+	flagSet.StringSliceVar(&targets, "targets", []string{"default.com"}, "Targets")
+
+	os.Args = []string{os.Args[0], "-cli", "cli"}
+	err := flagSet.Parse()
+	require.Nil(t, err, "could not parse flags")
+	require.Equal(t, 20, threads, "could not prefer higher priority source")
+	require.Equal(t, "low.txt", output)
+	require.Equal(t, "cli", cli)
+	require.Equal(t, StringSlice{"a.com", "b.com"}, targets)
+	require.Equal(t, "SOURCE(high)", flagSet.Source("t").String())
+
+	tearDown(t.Name())
+	flagSet = NewFlagSet()
+	flagSet.SetConfigFilePath("")
+	flagSet.AddSource(&mapSource{name: "invalid", values: map[string]string{"threads": "many"}}, 0)
+	flagSet.IntVar(&threads, "threads", 1, "Threads")
+	os.Args = []string{os.Args[0]}
+	err = flagSet.Parse()
+	require.NotNil(t, err, "could set invalid value from source")
+	require.Contains(t, err.Error(), "from invalid")
+
+	tearDown(t.Name())
+}
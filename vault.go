@@ -0,0 +1,93 @@
+package goflags
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// UseVault makes Parse resolve values like vault:secret/data/app#token set from the
+// environment or config files with the key of a HashiCorp Vault secret, where the
+// path is the API path of the secret for both KV version 1 and 2 engines.
+//
+// Vault is reached like with the vault CLI, using the VAULT_ADDR, VAULT_NAMESPACE
+// and VAULT_TOKEN environment variables or the ~/.vault-token file.
+func (flagSet *FlagSet) UseVault() {
+	flagSet.AddValueResolver("vault", resolveVaultSecret)
+}
+
+// resolveVaultSecret reads the key of a secret from a path#key reference
+func resolveVaultSecret(reference string) (string, error) {
+	separator := strings.LastIndex(reference, "#")
+	if separator < 0 {
+		return "", errors.Errorf("missing key in vault reference %q", reference)
+	}
+	secretPath, key := strings.TrimPrefix(reference[:separator], "/"), reference[separator+1:]
+
+	address := os.Getenv("VAULT_ADDR")
+	if address == "" {
+		address = "https://127.0.0.1:8200"
+	}
+	request, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(address, "/")+"/v1/"+secretPath, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "could not create vault request")
+	}
+	token, err := vaultToken()
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		request.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	response, err := remoteConfigClient(&RemoteConfigOptions{}).Do(request)
+	if err != nil {
+		return "", errors.Wrap(err, "could not reach vault")
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", errors.Errorf("could not read %s from vault: unexpected status %s", secretPath, response.Status)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&secret); err != nil {
+		return "", errors.Wrap(err, "could not decode vault secret")
+	}
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok && data["metadata"] != nil {
+		data = nested // KV version 2 secret
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", errors.Errorf("key %q not found in vault secret %s", key, secretPath)
+	}
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// vaultToken returns the Vault token of the environment or of the token helper file
+func vaultToken() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.New("no vault token, set VAULT_TOKEN")
+	}
+	token, err := ioutil.ReadFile(filepath.Join(homeDir, ".vault-token"))
+	if err != nil {
+		return "", errors.New("no vault token, set VAULT_TOKEN")
+	}
+	return strings.TrimSpace(string(token)), nil
+}
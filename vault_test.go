@@ -0,0 +1,72 @@
+package goflags
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			_, _ = w.Write([]byte(`{"data": {"data": {"token": "kv2 token"}, "metadata": {"version": 1}}}`))
+		case "/v1/kv/app":
+			_, _ = w.Write([]byte(`{"data": {"password": "kv1 password"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv("VAULT_ADDR", server.URL)
+	defer os.Unsetenv("VAULT_ADDR")
+	os.Setenv("VAULT_TOKEN", "root")
+	defer os.Unsetenv("VAULT_TOKEN")
+	os.Setenv("GOFLAGS_TEST_PASSWORD", "vault:kv/app#password")
+	defer os.Unsetenv("GOFLAGS_TEST_PASSWORD")
+
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = ioutil.WriteFile(configFile, []byte("token: vault:secret/data/app#token\nmissing: vault:secret/data/app#missing"), os.ModePerm)
+	require.Nil(t, err, "could not write config")
+
+	tearDown(t.Name())
+	flagSet := NewFlagSet()
+	flagSet.SetConfigFilePath(configFile)
+	flagSet.UseVault()
+	var token, password, cli string
+	flagSet.StringVar(&token, "token", "", "API token")
+	flagSet.StringVar(&password, "password", "", "Password").Env("GOFLAGS_TEST_PASSWORD")
+	flagSet.StringVar(&cli, "cli", "", "Cli value")
+
+	os.Args = []string{os.Args[0], "-cli", "vault:secret/data/app#token"}
+	err = flagSet.Parse()
+	require.Nil(t, err, "could not parse flags")
+	require.Equal(t, "kv2 token", token)
+	require.Equal(t, "kv1 password", password)
+	require.Equal(t, "vault:secret/data/app#token", cli, "could resolve cli value")
+
+	tearDown(t.Name())
+	flagSet = NewFlagSet()
+	flagSet.SetConfigFilePath(configFile)
+	flagSet.UseVault()
+	var missing string
+	flagSet.StringVar(&missing, "missing", "", "Missing value")
+	os.Args = []string{os.Args[0]}
+	err = flagSet.Parse()
+	require.NotNil(t, err, "could resolve missing vault key")
+
+	tearDown(t.Name())
+}
@@ -0,0 +1,60 @@
+package goflags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// versionFlagName is the name of the built-in flag printing the version
+const versionFlagName = "version"
+
+// ErrVersion is returned by Parse when the version was requested with -version
+var ErrVersion = errors.New("version requested")
+
+// versionInfo is the version of the program set with SetVersion
+type versionInfo struct {
+	version string
+	commit  string
+	date    string
+}
+
+func (info versionInfo) String() string {
+	var details []string
+	if info.commit != "" {
+		details = append(details, "commit "+info.commit)
+	}
+	if info.date != "" {
+		details = append(details, "built "+info.date)
+	}
+	if len(details) == 0 {
+		return info.version
+	}
+	return info.version + " (" + strings.Join(details, ", ") + ")"
+}
+
+// SetVersion sets the version of the program, shown in the usage and printed by
+// the built-in -version flag. The commit and build date are optional.
+//
+// When -version is provided, Parse prints the version and exits like for -help,
+// returning ErrVersion if the FlagSet continues on errors.
+func (flagSet *FlagSet) SetVersion(version, commit, date string) {
+	flagSet.version = versionInfo{version: version, commit: commit, date: date}
+}
+
+// registerVersionFlag registers the built-in -version flag if a version is set
+func (flagSet *FlagSet) registerVersionFlag() {
+	if flagSet.version.version == "" {
+		return
+	}
+	if _, ok := flagSet.flagKeys.values[versionFlagName]; ok || flagSet.CommandLine().Lookup(versionFlagName) != nil {
+		return
+	}
+	flagSet.BoolVar(&flagSet.showVersion, versionFlagName, false, "display the version of the program").skipConfig = true
+}
+
+// printVersion prints the name and version of the program
+func (flagSet *FlagSet) printVersion() {
+	fmt.Fprintf(flagSet.CommandLine().Output(), "%s %s\n", flagSet.appName(), flagSet.version)
+}
@@ -0,0 +1,37 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetVersion(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var target string
+	flagSet.AddPositional(&target, "target", "Target", true)
+	flagSet.SetVersion("v1.2.3", "abc1234", "2024-01-02")
+
+	output := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(output)
+	err := flagSet.ParseArgs([]string{"-version"})
+	require.Equal(t, ErrVersion, err)
+	require.Equal(t, flagSet.appName()+" v1.2.3 (commit abc1234, built 2024-01-02)\n", output.String())
+
+	output.Reset()
+	flagSet.usageFunc()
+	require.Contains(t, output.String(), flagSet.appName()+" v1.2.3 (commit abc1234, built 2024-01-02)\n")
+	require.Contains(t, output.String(), "display the version of the program")
+
+	code := -1
+	defer func(previous func(code int)) { exit = previous }(exit)
+	exit = func(exitCode int) { code = exitCode }
+	flagSet.SetErrorHandling(flag.ExitOnError)
+	flagSet.SetExitCode(3)
+	flagSet.Reset()
+	_ = flagSet.ParseArgs([]string{"-version"})
+	require.Equal(t, 0, code)
+}
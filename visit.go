@@ -0,0 +1,74 @@
+package goflags
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FlagInfo describes a registered flag
+type FlagInfo struct {
+	Name            string   `json:"name"` // long name of the flag, or its short name if it has no long name
+	Short           string   `json:"short,omitempty"`
+	Long            string   `json:"long,omitempty"`
+	Group           string   `json:"group,omitempty"` // name of the group of the flag, empty for flags not in a group
+	Type            string   `json:"type,omitempty"`  // name of the type of the value as shown in the usage, empty for bool flags
+	Usage           string   `json:"usage,omitempty"`
+	LongDescription string   `json:"longDescription,omitempty"`
+	DefaultValue    string   `json:"default,omitempty"`
+	DefaultText     string   `json:"defaultText,omitempty"` // text shown as the default value in the usage
+	HideDefault     bool     `json:"hideDefault,omitempty"`
+	Value           string   `json:"value,omitempty"` // current value, redacted for sensitive and password flags
+	Sensitive       bool     `json:"sensitive,omitempty"`
+	Hidden          bool     `json:"hidden,omitempty"`
+	Required        bool     `json:"required,omitempty"`
+	Deprecated      string   `json:"deprecated,omitempty"` // deprecation message, empty for flags which are not deprecated
+	EnvNames        []string `json:"env,omitempty"`
+	Choices         []string `json:"choices,omitempty"` // allowed values of enum flags
+}
+
+// VisitAll calls fn for every registered flag, in the order they were registered
+func (flagSet *FlagSet) VisitAll(fn func(info *FlagInfo)) {
+	flagSet.flagKeys.forEach(func(key string, data *FlagData) {
+		if key != data.name() || flagSet.CommandLine().Lookup(key) == nil {
+			return
+		}
+		fn(flagSet.flagInfo(data))
+	})
+}
+
+// flagInfo returns the description of a registered flag
+func (flagSet *FlagSet) flagInfo(data *FlagData) *FlagInfo {
+	currentFlag := *flagSet.CommandLine().Lookup(data.name())
+	currentFlag.Value = unwrapValue(currentFlag.Value)
+
+	info := &FlagInfo{
+		Name:            data.name(),
+		Short:           data.short,
+		Long:            data.long,
+		Group:           flagSet.usageGroup(data),
+		Usage:           data.usage,
+		LongDescription: data.longDescription,
+		Value:           currentFlag.Value.String(),
+		Sensitive:       data.sensitive || data.password,
+		Hidden:          data.hidden,
+		Required:        data.required,
+		Deprecated:      data.deprecated,
+		EnvNames:        data.envNames,
+		DefaultText:     data.defaultText,
+		HideDefault:     data.hideDefault,
+	}
+	if data.enum != nil {
+		info.Choices = data.enum.allowed
+	}
+	info.Type, _ = usageTypeName(&currentFlag, reflect.TypeOf(currentFlag.Value))
+	if slice, ok := currentFlag.Value.(*StringSlice); ok {
+		info.Value = slice.createStringArrayDefaultValue()
+	}
+	if data.defaultValue != nil {
+		info.DefaultValue = fmt.Sprint(data.defaultValue)
+	}
+	if info.Sensitive && info.Value != "" {
+		info.Value = redactedValue
+	}
+	return info
+}
@@ -0,0 +1,31 @@
+package goflags
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisitAll(t *testing.T) {
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var threads int
+	var token string
+	var targets StringSlice
+	flagSet.IntVarP(&threads, "threads", "t", 10, "Threads")
+	flagSet.StringVar(&token, "token", "", "API token").Sensitive()
+	flagSet.StringSliceVarP(&targets, "targets", "u", []string{"a.com"}, "Targets")
+
+	require.Nil(t, flagSet.ParseArgs([]string{"-t", "20", "-token", "secret"}), "could not parse flags")
+
+	var infos []FlagInfo
+	flagSet.VisitAll(func(info *FlagInfo) {
+		infos = append(infos, *info)
+	})
+	require.Equal(t, []FlagInfo{
+		{Name: "threads", Short: "t", Long: "threads", Type: "int", Usage: "Threads", DefaultValue: "10", Value: "20"},
+		{Name: "token", Long: "token", Type: "string", Usage: "API token", Value: redactedValue, Sensitive: true},
+		{Name: "targets", Short: "u", Long: "targets", Type: "string[]", Usage: "Targets", DefaultValue: `["a.com"]`, Value: `["a.com"]`},
+	}, infos[:3])
+}
@@ -0,0 +1,77 @@
+package goflags
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// minWrapWidth is the narrowest width usage descriptions are wrapped to
+const minWrapWidth = 20
+
+// wrapMarker marks the beginning of the description in the usage lines to wrap
+const wrapMarker = "\x01"
+
+// terminalWidth returns the width of the output if it is a terminal,
+// or 0 if it isn't, replaced in tests
+var terminalWidth = func(output io.Writer) int {
+	file, ok := output.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(file.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// escapeSequenceRegex matches the ANSI escape sequences of the usage colors
+var escapeSequenceRegex = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// visibleWidth returns the number of columns the text takes on a terminal
+func visibleWidth(text string) int {
+	return utf8.RuneCountInString(escapeSequenceRegex.ReplaceAllString(text, ""))
+}
+
+// wrapUsage wraps the descriptions of the usage lines beginning with the wrap
+// marker to the width, indenting the following lines to the description column.
+func wrapUsage(usage string, width int) string {
+	lines := strings.Split(usage, "\n")
+	for i, line := range lines {
+		index := strings.Index(line, wrapMarker)
+		if index < 0 {
+			continue
+		}
+		prefix, description := line[:index], line[index+len(wrapMarker):]
+		column := visibleWidth(prefix)
+		if width-column < minWrapWidth {
+			lines[i] = prefix + description
+			continue
+		}
+		lines[i] = prefix + strings.Join(wrapText(description, width-column), "\n"+strings.Repeat(" ", column))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapText splits the text in lines of at most width columns, breaking it between words
+func wrapText(text string, width int) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Split(text, " ") {
+		if line != "" && visibleWidth(line)+1+visibleWidth(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,33 @@
+package goflags
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageWrapping(t *testing.T) {
+	defer func(previous func(output io.Writer) int) { terminalWidth = previous }(terminalWidth)
+	terminalWidth = func(output io.Writer) int { return 50 }
+
+	flagSet := NewScopedFlagSet(t.Name(), flag.ContinueOnError)
+	flagSet.SetConfigFilePath("")
+	var threads int
+	var verbose bool
+	flagSet.IntVarP(&threads, "threads", "t", 10, "Number of concurrent threads used to send the requests")
+	flagSet.BoolVar(&verbose, "verbose", false, "Verbose output")
+
+	usage := &bytes.Buffer{}
+	flagSet.CommandLine().SetOutput(usage)
+	flagSet.usageFunc()
+	require.Contains(t, usage.String(), "Flags:\n"+
+		"   -t, -threads int  Number of concurrent threads\n"+
+		"                     used to send the requests\n"+
+		"                     (default 10)\n"+
+		"   -verbose          Verbose output\n")
+
+	require.Equal(t, "  -x  a b", wrapUsage("  -x  "+wrapMarker+"a b", 10), "narrow descriptions were wrapped")
+}
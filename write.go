@@ -0,0 +1,64 @@
+package goflags
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// defaultConfigFileMode is the default permission of the config files written
+// by the library, which frequently contain API keys.
+const defaultConfigFileMode os.FileMode = 0600
+
+// SetConfigFilePermissions sets the permission of the config files written by
+// the library. Directories created for them get the same permission, with the
+// execute bit added for each class allowed to read.
+func (flagSet *FlagSet) SetConfigFilePermissions(mode os.FileMode) {
+	flagSet.configFileMode = mode.Perm()
+}
+
+// configDirMode returns the permission of the directories created for config files
+func (flagSet *FlagSet) configDirMode() os.FileMode {
+	return flagSet.configFileMode | (flagSet.configFileMode&0444)>>2
+}
+
+// saveConfigFile atomically replaces the config file at path with content by
+// writing a temporary file in the same directory and renaming it over the
+// original. If backup is set, an existing file is first copied to path.bak.
+func (flagSet *FlagSet) saveConfigFile(path string, content []byte, backup bool) error {
+	if backup {
+		original, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "could not read config file")
+		}
+		if err == nil {
+			if err := flagSet.saveConfigFile(path+".bak", original, false); err != nil {
+				return errors.Wrap(err, "could not backup config file")
+			}
+		}
+	}
+
+	tempFile, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "could not create temporary config file")
+	}
+	defer os.Remove(tempFile.Name()) // no-op once renamed
+
+	if _, err := tempFile.Write(content); err != nil {
+		tempFile.Close()
+		return errors.Wrap(err, "could not write config file")
+	}
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
+		return errors.Wrap(err, "could not sync config file")
+	}
+	if err := tempFile.Close(); err != nil {
+		return errors.Wrap(err, "could not close config file")
+	}
+	if err := os.Chmod(tempFile.Name(), flagSet.configFileMode); err != nil {
+		return errors.Wrap(err, "could not set config file permissions")
+	}
+	return errors.Wrap(os.Rename(tempFile.Name(), path), "could not replace config file")
+}
@@ -0,0 +1,60 @@
+package goflags
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveConfigFile(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	flagSet := NewFlagSet()
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = flagSet.saveConfigFile(configFile, []byte("threads: 1"), true)
+	require.Nil(t, err, "could not write config file")
+	_, err = os.Stat(configFile + ".bak")
+	require.True(t, os.IsNotExist(err), "could backup missing config file")
+
+	err = flagSet.saveConfigFile(configFile, []byte("threads: 2"), true)
+	require.Nil(t, err, "could not overwrite config file")
+
+	content, err := ioutil.ReadFile(configFile)
+	require.Nil(t, err, "could not read config file")
+	require.Equal(t, "threads: 2", string(content))
+	backup, err := ioutil.ReadFile(configFile + ".bak")
+	require.Nil(t, err, "could not read backup file")
+	require.Equal(t, "threads: 1", string(backup))
+
+	files, err := ioutil.ReadDir(tempDir)
+	require.Nil(t, err, "could not list directory")
+	require.Len(t, files, 2, "could not remove temporary files")
+}
+
+func TestSetConfigFilePermissions(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "goflags-")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	flagSet := NewFlagSet()
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err = flagSet.saveConfigFile(configFile, []byte("threads: 1"), false)
+	require.Nil(t, err, "could not write config file")
+	info, err := os.Stat(configFile)
+	require.Nil(t, err, "could not stat config file")
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	require.Equal(t, os.FileMode(0700), flagSet.configDirMode())
+
+	flagSet.SetConfigFilePermissions(0640)
+	err = flagSet.saveConfigFile(configFile, []byte("threads: 2"), false)
+	require.Nil(t, err, "could not write config file")
+	info, err = os.Stat(configFile)
+	require.Nil(t, err, "could not stat config file")
+	require.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	require.Equal(t, os.FileMode(0750), flagSet.configDirMode())
+}